@@ -2,6 +2,7 @@ package main
 
 import (
 	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
 	"campuscash-backend/internal/middleware"
 	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/repository"
@@ -19,28 +20,78 @@ func main() {
 
 	gin.SetMode(config.GinMode)
 
-	db, err := gorm.Open(sqlite.Open(config.DBPath), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(config.SQLiteDSN(config.DBPath)), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	if err := db.AutoMigrate(&model.User{}, &model.Reward{}, &model.Transaction{}, &model.Institution{}, &model.Coupon{}, &model.Notification{}); err != nil {
+	// Bancos anteriores à verificação de email: os usuários existentes entram verificados
+	legacyUsers := db.Migrator().HasTable(&model.User{}) && !db.Migrator().HasColumn(&model.User{}, "EmailVerifiedAt")
+
+	if err := db.AutoMigrate(&model.User{}, &model.Reward{}, &model.Transaction{}, &model.Institution{}, &model.Coupon{}, &model.Notification{}, &model.LedgerAccount{}, &model.JournalEntry{}, &model.Posting{}, &model.IdempotencyKey{}, &model.CoinLot{}, &model.CoinLotConsumption{}, &model.Semester{}, &model.AllotmentPolicy{}, &model.AllotmentCredit{}, &model.ScheduledJob{}, &model.JobRun{}, &model.AuditLog{}, &model.Invitation{}, &model.Session{}, &model.RefreshToken{}, &model.UserToken{}, &model.MFACredential{}, &model.RecoveryCode{}, &model.MFAChallenge{}, &model.LoginAttempt{}, &model.Course{}, &model.Class{}, &model.Enrollment{}, &model.RewardInstitution{}, &model.RewardWaitlist{}, &model.RewardFavorite{}, &model.CompanySigningKey{}, &model.CouponValidation{}, &model.Branch{}, &model.RewardBranch{}); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	if legacyUsers {
+		if err := service.BackfillEmailVerification(db); err != nil {
+			log.Fatal("Failed to backfill email verification:", err)
+		}
+	}
+	if err := service.MigrateUserInstitutions(db); err != nil {
+		log.Fatal("Failed to migrate user institutions:", err)
+	}
+	if err := service.BackfillCouponStatus(db); err != nil {
+		log.Fatal("Failed to backfill coupon status:", err)
+	}
+	if err := service.NormalizeUserDocuments(db); err != nil {
+		log.Fatal("Failed to normalize user documents:", err)
+	}
+	if err := service.CheckSigningKeySecret(db); err != nil {
+		log.Fatal("Failed to open coupon signing keys:", err)
+	}
+	if err := service.CheckMFASecret(db); err != nil {
+		log.Fatal("Failed to open MFA secrets:", err)
+	}
 	service.SeedAll(db)
+	if config.SeedDemo {
+		service.SeedTurmas(db)
+	}
+
+	ledgerSvc := service.NewLedgerService(db)
+	if err := ledgerSvc.Bootstrap(); err != nil {
+		log.Fatal("Failed to bootstrap ledger:", err)
+	}
+	if discrepancies, err := ledgerSvc.Verify(); err == nil && len(discrepancies) > 0 {
+		log.Printf("Warning: %d ledger accounts diverge from their postings", len(discrepancies))
+	}
 
 	// Inicializar serviços
 	notificationRepo := repository.NewNotificationRepository(db)
 	notificationSvc := service.NewNotificationService(notificationRepo)
-	cronSvc := service.NewCronService(db, notificationSvc)
-	
-	// Iniciar cronjob para distribuir moedas aos professores
-	cronSvc.StartCronJob()
+	coinExpirySvc := service.NewCoinExpiryService(db, notificationSvc)
+	if err := coinExpirySvc.SyncUntrackedBalances(); err != nil {
+		log.Printf("Error syncing coin lots: %v", err)
+	}
+
+	loginGuard := service.NewLoginGuard(service.NewLoginAttemptStore(db), db, notificationSvc)
+
+	// Iniciar jobs agendados (créditos semestrais, conciliação e expiração de moedas)
+	scheduler := service.NewScheduler(db)
+	if err := service.RegisterJobs(scheduler, db, notificationSvc, loginGuard); err != nil {
+		log.Fatal("Failed to register jobs:", err)
+	}
+	scheduler.Start()
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(config.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 	r.Use(middleware.Cors())
-	route.RegisterRoutes(r, db)
+	r.Use(middleware.ErrorHandler())
+	r.NoRoute(func(c *gin.Context) {
+		c.Error(apperror.ErrNotFound)
+	})
+	route.RegisterRoutes(r, db, scheduler, loginGuard)
 
 	log.Printf("Starting server on port %s", config.Port)
 	if err := r.Run(":" + config.Port); err != nil {
@@ -4,10 +4,19 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
 var (
 	JWTSecret []byte
+	// Cifra as chaves privadas de assinatura de cupons; independente do JWT_SECRET
+	// para que ele possa ser trocado sem invalidar as chaves
+	CouponKeySecret []byte
+	// Cifra os segredos TOTP, pelo mesmo motivo
+	MFAKeySecret []byte
+
+	AccessTokenMinutes int
+	RefreshTokenDays   int
 
 	DBPath string
 
@@ -21,13 +30,73 @@ var (
 
 	CronSecret string
 
+	AdminEmail    string
+	AdminPassword string
+
+	SeedDemo bool
+
+	FrontendURL        string
+	InvitationTTLHours int
+
+	RequireEmailVerification  bool
+	EmailVerificationTTLHours int
+	PasswordResetTTLMinutes   int
+
+	MFAIssuer           string
+	MFARequiredRoles    []string
+	MFAChallengeMinutes int
+
+	LoginAttemptStore         string
+	LoginMaxFailures          int
+	LoginIPMaxFailures        int
+	LoginLockoutMinutes       int
+	LoginFailureWindowMinutes int
+	LoginBackoffMaxSeconds    int
+	TrustedProxies            []string
+
 	MaxImageSize      int64
 	AllowedImageTypes []string
 
-	CronIntervalSeconds int
-	CronCoinsAmount     uint
+	SemesterAllotment uint
+
+	AllotmentJobSchedule    string
+	ReconcileJobSchedule    string
+	CoinExpiryJobSchedule   string
+	RewardLaunchJobSchedule string
+	CouponExpiryJobSchedule string
+	JobLeaseSeconds         int
+
+	ReconcileAutoRepair bool
+
+	IdempotencyTTLHours int
+
+	ReversalWindowHours int
+
+	CoinExpiryDays       int
+	CoinExpiryNoticeDays int
 )
 
+// sqlitePragmas: escritores entram em fila já no BEGIN (_txlock=immediate) e esperam
+// até 10s pela trava; o WAL deixa as leituras seguirem durante a escrita.
+var sqlitePragmas = []string{"_busy_timeout=10000", "_txlock=immediate", "_journal_mode=WAL"}
+
+// SQLiteDSN acrescenta ao caminho do banco as opções de sqlitePragmas que ele ainda
+// não define. É o DSN de produção e dos testes.
+func SQLiteDSN(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	for _, pragma := range sqlitePragmas {
+		if strings.Contains(path, pragma[:strings.Index(pragma, "=")+1]) {
+			continue
+		}
+		path += sep + pragma
+		sep = "&"
+	}
+	return path
+}
+
 func LoadConfig() {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -36,9 +105,41 @@ func LoadConfig() {
 	}
 	JWTSecret = []byte(jwtSecret)
 
+	// Instalações anteriores cifraram as chaves com o JWT_SECRET: sem a variável, ele
+	// continua valendo. Antes de trocar o JWT_SECRET, copie o valor atual para cá.
+	couponKeySecret := os.Getenv("COUPON_KEY_SECRET")
+	if couponKeySecret == "" {
+		couponKeySecret = jwtSecret
+		log.Println("Warning: COUPON_KEY_SECRET not set, coupon signing keys are encrypted with JWT_SECRET. Set it before rotating JWT_SECRET!")
+	}
+	CouponKeySecret = []byte(couponKeySecret)
+
+	mfaKeySecret := os.Getenv("MFA_KEY_SECRET")
+	if mfaKeySecret == "" {
+		mfaKeySecret = jwtSecret
+		log.Println("Warning: MFA_KEY_SECRET not set, TOTP secrets are encrypted with JWT_SECRET. Set it before rotating JWT_SECRET!")
+	}
+	MFAKeySecret = []byte(mfaKeySecret)
+
+	AccessTokenMinutes = 15 // Default: 15 minutos
+	if minutesStr := os.Getenv("ACCESS_TOKEN_MINUTES"); minutesStr != "" {
+		if minutes, err := strconv.Atoi(minutesStr); err == nil && minutes > 0 {
+			AccessTokenMinutes = minutes
+		}
+	}
+
+	RefreshTokenDays = 30 // Default: 30 dias
+	if daysStr := os.Getenv("REFRESH_TOKEN_DAYS"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days > 0 {
+			RefreshTokenDays = days
+		}
+	}
+
+	// Sem cache=shared: com ele o SQLite responde "locked" na hora em vez de respeitar
+	// o _busy_timeout de SQLiteDSN
 	DBPath = os.Getenv("DB_PATH")
 	if DBPath == "" {
-		DBPath = "file:campuscash.db?cache=shared"
+		DBPath = "file:campuscash.db"
 	}
 
 	SMTPHost = os.Getenv("SMTP_HOST")
@@ -71,17 +172,185 @@ func LoadConfig() {
 		CronSecret = "demo-secret-123"
 	}
 
-	CronIntervalSeconds = 15 // Default: 15 segundos
-	if intervalStr := os.Getenv("CRON_INTERVAL_SECONDS"); intervalStr != "" {
-		if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
-			CronIntervalSeconds = interval
+	SemesterAllotment = 1000 // Default: 1000 moedas por semestre
+	if amountStr := os.Getenv("SEMESTER_ALLOTMENT"); amountStr != "" {
+		if amount, err := strconv.ParseUint(amountStr, 10, 32); err == nil {
+			SemesterAllotment = uint(amount)
 		}
 	}
 
-	CronCoinsAmount = 100 // Default: 100 moedas
-	if amountStr := os.Getenv("CRON_COINS_AMOUNT"); amountStr != "" {
-		if amount, err := strconv.ParseUint(amountStr, 10, 32); err == nil {
-			CronCoinsAmount = uint(amount)
+	AdminEmail = os.Getenv("ADMIN_EMAIL")
+	if AdminEmail == "" {
+		AdminEmail = "admin@demo.com"
+	}
+	// Sem senha padrão: vazio, o admin inicial só é criado no modo demonstração (ver SeedAdmin)
+	AdminPassword = os.Getenv("ADMIN_PASSWORD")
+
+	// Modo demonstração: cria turmas com todos os alunos da instituição matriculados
+	SeedDemo = os.Getenv("SEED_DEMO") == "true"
+
+	// Usado nos links enviados por email
+	FrontendURL = os.Getenv("FRONTEND_URL")
+	if FrontendURL == "" {
+		FrontendURL = "http://localhost:3000"
+	}
+
+	InvitationTTLHours = 72 // Default: 3 dias
+	if ttlStr := os.Getenv("INVITATION_TTL_HOURS"); ttlStr != "" {
+		if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl > 0 {
+			InvitationTTLHours = ttl
+		}
+	}
+
+	RequireEmailVerification = os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+
+	EmailVerificationTTLHours = 48 // Default: 2 dias
+	if ttlStr := os.Getenv("EMAIL_VERIFICATION_TTL_HOURS"); ttlStr != "" {
+		if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl > 0 {
+			EmailVerificationTTLHours = ttl
+		}
+	}
+
+	PasswordResetTTLMinutes = 60 // Default: 1 hora
+	if ttlStr := os.Getenv("PASSWORD_RESET_TTL_MINUTES"); ttlStr != "" {
+		if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl > 0 {
+			PasswordResetTTLMinutes = ttl
+		}
+	}
+
+	MFAIssuer = os.Getenv("MFA_ISSUER")
+	if MFAIssuer == "" {
+		MFAIssuer = "CampusCash"
+	}
+
+	// Papéis que não podem entrar sem o segundo fator, ex.: "company,admin"
+	MFARequiredRoles = nil
+	for _, role := range strings.Split(os.Getenv("MFA_REQUIRED_ROLES"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			MFARequiredRoles = append(MFARequiredRoles, role)
+		}
+	}
+
+	MFAChallengeMinutes = 5 // Default: 5 minutos
+	if ttlStr := os.Getenv("MFA_CHALLENGE_MINUTES"); ttlStr != "" {
+		if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl > 0 {
+			MFAChallengeMinutes = ttl
+		}
+	}
+
+	// "db" guarda os contadores de falhas de login no banco; "memory" só no processo
+	LoginAttemptStore = os.Getenv("LOGIN_ATTEMPT_STORE")
+	if LoginAttemptStore == "" {
+		LoginAttemptStore = "db"
+	}
+
+	LoginMaxFailures = 5 // Default: bloqueia a conta na 5ª falha
+	if maxStr := os.Getenv("LOGIN_MAX_FAILURES"); maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max > 0 {
+			LoginMaxFailures = max
+		}
+	}
+
+	LoginIPMaxFailures = 50 // Default: mais alto, pois redes do campus compartilham IP
+	if maxStr := os.Getenv("LOGIN_IP_MAX_FAILURES"); maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max > 0 {
+			LoginIPMaxFailures = max
+		}
+	}
+
+	LoginLockoutMinutes = 15 // Default: 15 minutos
+	if minutesStr := os.Getenv("LOGIN_LOCKOUT_MINUTES"); minutesStr != "" {
+		if minutes, err := strconv.Atoi(minutesStr); err == nil && minutes > 0 {
+			LoginLockoutMinutes = minutes
+		}
+	}
+
+	LoginFailureWindowMinutes = 60 // Default: 1 hora sem falhas zera o contador
+	if minutesStr := os.Getenv("LOGIN_FAILURE_WINDOW_MINUTES"); minutesStr != "" {
+		if minutes, err := strconv.Atoi(minutesStr); err == nil && minutes > 0 {
+			LoginFailureWindowMinutes = minutes
+		}
+	}
+	// A janela não pode acabar antes do bloqueio, senão o contador zeraria durante ele
+	if LoginFailureWindowMinutes < LoginLockoutMinutes {
+		LoginFailureWindowMinutes = LoginLockoutMinutes
+	}
+
+	LoginBackoffMaxSeconds = 60 // Default: espera máxima entre tentativas antes do bloqueio
+	if secondsStr := os.Getenv("LOGIN_BACKOFF_MAX_SECONDS"); secondsStr != "" {
+		if seconds, err := strconv.Atoi(secondsStr); err == nil && seconds > 0 {
+			LoginBackoffMaxSeconds = seconds
+		}
+	}
+
+	// Proxies cujo X-Forwarded-For é aceito para descobrir o IP do cliente; vazio = nenhum
+	TrustedProxies = nil
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			TrustedProxies = append(TrustedProxies, proxy)
+		}
+	}
+
+	// Expressões cron (5 campos) ou descritores como @hourly e "@every 30m"
+	AllotmentJobSchedule = os.Getenv("JOB_ALLOTMENT_SCHEDULE")
+	if AllotmentJobSchedule == "" {
+		AllotmentJobSchedule = "*/15 * * * *" // Default: a cada 15 minutos
+	}
+
+	ReconcileJobSchedule = os.Getenv("JOB_RECONCILE_SCHEDULE")
+	if ReconcileJobSchedule == "" {
+		ReconcileJobSchedule = "@hourly"
+	}
+
+	CoinExpiryJobSchedule = os.Getenv("JOB_COIN_EXPIRY_SCHEDULE")
+	if CoinExpiryJobSchedule == "" {
+		CoinExpiryJobSchedule = "@hourly"
+	}
+
+	RewardLaunchJobSchedule = os.Getenv("JOB_REWARD_LAUNCH_SCHEDULE")
+	if RewardLaunchJobSchedule == "" {
+		RewardLaunchJobSchedule = "*/5 * * * *"
+	}
+
+	CouponExpiryJobSchedule = os.Getenv("JOB_COUPON_EXPIRY_SCHEDULE")
+	if CouponExpiryJobSchedule == "" {
+		CouponExpiryJobSchedule = "*/15 * * * *"
+	}
+
+	JobLeaseSeconds = 600 // Default: 10 minutos
+	if leaseStr := os.Getenv("JOB_LEASE_SECONDS"); leaseStr != "" {
+		if lease, err := strconv.Atoi(leaseStr); err == nil && lease > 0 {
+			JobLeaseSeconds = lease
+		}
+	}
+
+	ReconcileAutoRepair = os.Getenv("RECONCILE_AUTO_REPAIR") == "true"
+
+	IdempotencyTTLHours = 24 // Default: 24 horas
+	if ttlStr := os.Getenv("IDEMPOTENCY_TTL_HOURS"); ttlStr != "" {
+		if ttl, err := strconv.Atoi(ttlStr); err == nil && ttl > 0 {
+			IdempotencyTTLHours = ttl
+		}
+	}
+
+	ReversalWindowHours = 24 // Default: 24 horas
+	if windowStr := os.Getenv("REVERSAL_WINDOW_HOURS"); windowStr != "" {
+		if window, err := strconv.Atoi(windowStr); err == nil && window > 0 {
+			ReversalWindowHours = window
+		}
+	}
+
+	CoinExpiryDays = 180 // Default: aproximadamente um semestre
+	if daysStr := os.Getenv("COIN_EXPIRY_DAYS"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days > 0 {
+			CoinExpiryDays = days
+		}
+	}
+
+	CoinExpiryNoticeDays = 7
+	if daysStr := os.Getenv("COIN_EXPIRY_NOTICE_DAYS"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days >= 0 {
+			CoinExpiryNoticeDays = days
 		}
 	}
 
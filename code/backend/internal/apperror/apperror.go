@@ -0,0 +1,79 @@
+package apperror
+
+import (
+	"net/http"
+)
+
+// Error é o erro de aplicação devolvido pelos services e convertido em resposta pelo
+// middleware.ErrorHandler. Code é estável (o frontend pode decidir por ele) e Key escolhe
+// a mensagem traduzida; por padrão as duas coincidem.
+type Error struct {
+	Code    string
+	Status  int
+	Key     string
+	Details map[string]interface{}
+	cause   error
+}
+
+func New(code string, status int) *Error {
+	return &Error{Code: code, Status: status, Key: code}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Code + ": " + e.cause.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is compara pelo código, para que as cópias criadas por With/Wrap continuem
+// casando com o sentinel em errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	c.Details = make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+	return &c
+}
+
+// With devolve uma cópia com mais um detalhe; os detalhes vão na resposta e
+// preenchem os marcadores da mensagem ("{retryAfter}").
+func (e *Error) With(key string, value interface{}) *Error {
+	c := e.clone()
+	c.Details[key] = value
+	return c
+}
+
+// WithKey devolve uma cópia que usa outra mensagem para o mesmo código.
+func (e *Error) WithKey(key string) *Error {
+	c := e.clone()
+	c.Key = key
+	return c
+}
+
+// Wrap guarda a causa original, que só aparece no log.
+func (e *Error) Wrap(cause error) *Error {
+	c := e.clone()
+	c.cause = cause
+	return c
+}
+
+var (
+	ErrInternal     = New("internal_error", http.StatusInternalServerError)
+	ErrInvalidBody  = New("invalid_request", http.StatusBadRequest)
+	ErrInvalidID    = New("invalid_id", http.StatusBadRequest)
+	ErrValidation   = New("validation_failed", http.StatusBadRequest)
+	ErrConflict     = New("conflict", http.StatusConflict)
+	ErrUnauthorized = New("unauthorized", http.StatusUnauthorized)
+	ErrNotFound     = New("not_found", http.StatusNotFound)
+)
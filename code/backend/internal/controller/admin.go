@@ -0,0 +1,321 @@
+package controller
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func pagination(c *gin.Context) (int, int) {
+	limit := 20
+	offset := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+	return limit, offset
+}
+
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.Error(apperror.ErrInvalidID)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func optionalUint(c *gin.Context, key string) *uint {
+	if v, err := strconv.Atoi(c.Query(key)); err == nil && v > 0 {
+		id := uint(v)
+		return &id
+	}
+	return nil
+}
+
+func AdminListUsers(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, offset := pagination(c)
+		users, err := svc.ListUsers(c.Query("q"), c.Query("role"), optionalUint(c, "institutionId"), limit, offset)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, users)
+	}
+}
+
+func AdminGetUser(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		user, err := svc.GetUser(id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func AdminCreateUser(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.AdminUserCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		user, err := svc.CreateUser(c.GetUint("userID"), input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, user)
+	}
+}
+
+func AdminSetUserActive(svc *service.AdminService, active bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		user, err := svc.SetActive(c.GetUint("userID"), id, active)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func AdminResetPassword(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		result, err := svc.ResetPassword(c.GetUint("userID"), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func AdminUnlockUser(guard *service.LoginGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := guard.Unlock(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"unlocked": true})
+	}
+}
+
+func AdminChangeRole(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.AdminRoleUpdateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		user, err := svc.ChangeRole(c.GetUint("userID"), id, input.Role)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+func AdminUserLedger(svc *service.LedgerService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		statement, err := svc.Statement(id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, statement)
+	}
+}
+
+func AdminListInstitutions(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		institutions, err := svc.ListInstitutions()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, institutions)
+	}
+}
+
+func AdminCreateInstitution(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.InstitutionSaveDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		inst, err := svc.CreateInstitution(c.GetUint("userID"), input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, inst)
+	}
+}
+
+func AdminUpdateInstitution(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.InstitutionSaveDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		inst, err := svc.UpdateInstitution(c.GetUint("userID"), id, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, inst)
+	}
+}
+
+func AdminDeleteInstitution(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.DeleteInstitution(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deleted": true})
+	}
+}
+
+func AdminListRewards(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rewards, err := svc.ListRewards(c.Query("status"), optionalUint(c, "companyId"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, rewards)
+	}
+}
+
+func AdminSuspendReward(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.RewardModerationDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		reward, err := svc.SuspendReward(c.GetUint("userID"), id, input.Reason)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, reward)
+	}
+}
+
+func AdminRestoreReward(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		reward, err := svc.RestoreReward(c.GetUint("userID"), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, reward)
+	}
+}
+
+func AdminLedgerAccounts(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accounts, err := svc.LedgerAccounts(c.Query("kind"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, accounts)
+	}
+}
+
+func AdminJournalEntries(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, offset := pagination(c)
+		entries, err := svc.JournalEntries(optionalUint(c, "accountId"), limit, offset)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, entries)
+	}
+}
+
+func AdminLedgerVerify(svc *service.LedgerService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		discrepancies, err := svc.Verify()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, discrepancies)
+	}
+}
+
+func AdminAuditLog(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, offset := pagination(c)
+		entries, err := svc.AuditLog(service.AuditFilter{
+			ActorID:    optionalUint(c, "actorId"),
+			Action:     c.Query("action"),
+			TargetType: c.Query("targetType"),
+			TargetID:   optionalUint(c, "targetId"),
+			Limit:      limit,
+			Offset:     offset,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, entries)
+	}
+}
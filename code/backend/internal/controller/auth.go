@@ -2,12 +2,18 @@ package controller
 
 import (
 	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
 	"campuscash-backend/internal/model"
+	"campuscash-backend/internal/service"
+	"campuscash-backend/pkg/validator"
+	"errors"
+	"log"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -22,29 +28,55 @@ type SignupStudentInput struct {
 	Password    string `json:"password" binding:"required"`
 }
 
-func SignupAluno(db *gorm.DB) gin.HandlerFunc {
+func (in *SignupStudentInput) Validate() validator.Errors {
+	var errs validator.Errors
+	errs.Required("name", &in.Name)
+	errs.Email("email", &in.Email)
+	errs.CPF("cpf", &in.CPF)
+	errs.Required("registration", &in.Registration)
+	errs.Required("institution", &in.Institution)
+	errs.Required("course", &in.Course)
+	errs.Password("password", in.Password)
+	return errs
+}
+
+func SignupAluno(db *gorm.DB, accountSvc *service.AccountService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input SignupStudentInput
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := service.EnsureUniqueUser(db, 0, input.Email, input.CPF, "cpf"); err != nil {
+			c.Error(err)
+			return
+		}
+		inst, err := service.ResolveInstitution(db, input.Institution)
+		if err != nil {
+			if errors.Is(err, service.ErrInstitutionNotFound) {
+				err = validator.Errors{{Field: "institution", Code: validator.CodeInvalid}}
+			}
+			c.Error(err)
 			return
 		}
 		hash, _ := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 		student := model.User{
-			Name:         input.Name,
-			Email:        input.Email,
-			PasswordHash: string(hash),
-			CPF:          &input.CPF,
-			Registration: &input.Registration,
-			Institution:  &input.Institution,
-			Course:       &input.Course,
-			Role:         model.StudentRole,
-			Balance:      0,
+			Name:          input.Name,
+			Email:         input.Email,
+			PasswordHash:  string(hash),
+			CPF:           &input.CPF,
+			Registration:  &input.Registration,
+			InstitutionID: &inst.ID,
+			Course:        &input.Course,
+			Role:          model.StudentRole,
+			Balance:       0,
 		}
 		if err := db.Create(&student).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Error(service.UniqueViolation(err, "cpf"))
 			return
 		}
+		if err := accountSvc.SendVerification(&student); err != nil {
+			log.Printf("Error issuing email verification for user %d: %v", student.ID, err)
+		}
 		// Remove password hash antes de retornar
 		student.PasswordHash = ""
 		c.JSON(http.StatusCreated, student)
@@ -56,39 +88,189 @@ type LoginInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
-func Login(db *gorm.DB) gin.HandlerFunc {
+func Login(db *gorm.DB, sessionSvc *service.SessionService, mfaSvc *service.MFAService, guard *service.LoginGuard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input LoginInput
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
+			return
+		}
+		// Emails são gravados em minúsculas; contas antigas podem ter maiúsculas
+		input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+		if err := guard.Check(input.Email, c.ClientIP()); err != nil {
+			loginBlocked(c, err)
 			return
 		}
 		var user model.User
-		if err := db.Where("email = ?", input.Email).First(&user).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "credenciais inválidas"})
+		if err := db.Where("LOWER(email) = ?", input.Email).First(&user).Error; err != nil {
+			guard.Fail(input.Email, c.ClientIP())
+			c.Error(errInvalidCredentials)
+			return
+		}
+		// Contas criadas por convite ficam sem senha até a ativação
+		if user.PasswordHash == "" {
+			c.Error(errAccountPending)
 			return
 		}
 		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "credenciais inválidas"})
+			guard.Fail(input.Email, c.ClientIP())
+			c.Error(errInvalidCredentials)
+			return
+		}
+		if user.DeactivatedAt != nil || (user.Role == model.StaffUserRole && !service.CompanyActive(db, user.CompanyID)) {
+			c.Error(errAccountDisabled)
 			return
 		}
-		claims := jwt.MapClaims{
-			"id":   user.ID,
-			"role": user.Role,
-			"exp":  time.Now().Add(time.Hour * 24 * 7).Unix(),
+		if config.RequireEmailVerification && user.EmailVerifiedAt == nil {
+			c.Error(errEmailNotVerified)
+			return
 		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, _ := token.SignedString(config.JWTSecret)
-		user.PasswordHash = ""
-		c.JSON(http.StatusOK, gin.H{
-			"token": tokenString,
-			"user": gin.H{
-				"id":    user.ID,
-				"name":  user.Name,
-				"email": user.Email,
-				"role":  user.Role,
-			},
-		})
+		needsMFA, err := mfaSvc.NeedsChallenge(&user)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if needsMFA {
+			challenge, err := mfaSvc.Challenge(&user)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			c.JSON(http.StatusOK, challenge)
+			return
+		}
+		guard.Succeed(user.Email)
+		startSession(c, sessionSvc, &user, nil)
+	}
+}
+
+// loginBlocked registra o erro de Check e, quando for um bloqueio, informa o Retry-After.
+func loginBlocked(c *gin.Context, err error) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		if seconds, ok := appErr.Details["retryAfter"].(int); ok {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+	}
+	c.Error(err)
+}
+
+// startSession abre a sessão e responde no formato do login. extra é mesclado à resposta.
+func startSession(c *gin.Context, sessionSvc *service.SessionService, user *model.User, extra gin.H) {
+	tokens, err := sessionSvc.Issue(user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	profile := gin.H{
+		"id":    user.ID,
+		"name":  user.Name,
+		"email": user.Email,
+		"role":  user.Role,
+	}
+	if companyID, perms := model.CompanyScope(user); companyID != 0 {
+		profile["companyId"] = companyID
+		profile["permissions"] = perms
+	}
+	response := gin.H{
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"user":         profile,
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func RefreshSession(sessionSvc *service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.RefreshTokenDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		tokens, err := sessionSvc.Refresh(input.RefreshToken)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, tokens)
+	}
+}
+
+func Logout(sessionSvc *service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := sessionSvc.Revoke(c.GetString("sessionID")); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"loggedOut": true})
+	}
+}
+
+func LogoutAll(sessionSvc *service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := sessionSvc.RevokeAll(c.GetUint("userID")); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"loggedOut": true})
+	}
+}
+
+func VerifyEmail(accountSvc *service.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.TokenDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := accountSvc.VerifyEmail(input.Token); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"verified": true})
+	}
+}
+
+func ResendVerification(accountSvc *service.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.EmailDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := accountSvc.ResendVerification(input.Email); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "se o email estiver cadastrado, um novo link foi enviado"})
+	}
+}
+
+func ForgotPassword(accountSvc *service.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.EmailDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := accountSvc.ForgotPassword(input.Email); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "se o email estiver cadastrado, enviaremos um link de redefinição"})
+	}
+}
+
+func ResetPassword(accountSvc *service.AccountService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.PasswordResetDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := accountSvc.ResetPassword(input.Token, input.Password); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"reset": true})
 	}
 }
 
@@ -97,7 +279,7 @@ func GetMe(db *gorm.DB) gin.HandlerFunc {
 		userID := c.GetUint("userID")
 		var user model.User
 		if err := db.First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "usuário não encontrado"})
+			c.Error(service.ErrUserNotFound)
 			return
 		}
 
@@ -109,17 +291,29 @@ func GetMe(db *gorm.DB) gin.HandlerFunc {
 
 type SignupCompanyInput struct {
 	Name        string `json:"name" binding:"required"`
-	Email       string `json:"email" binding:"required,email"`
+	Email       string `json:"email" binding:"required"`
 	Password    string `json:"password" binding:"required"`
 	CNPJ        string `json:"cnpj" binding:"required"`
 	Description string `json:"description"`
 }
 
-func SignupCompany(db *gorm.DB) gin.HandlerFunc {
+func (in *SignupCompanyInput) Validate() validator.Errors {
+	var errs validator.Errors
+	errs.Required("name", &in.Name)
+	errs.Email("email", &in.Email)
+	errs.Password("password", in.Password)
+	errs.CNPJ("cnpj", &in.CNPJ)
+	return errs
+}
+
+func SignupCompany(db *gorm.DB, accountSvc *service.AccountService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input SignupCompanyInput
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := service.EnsureUniqueUser(db, 0, input.Email, input.CNPJ, "cnpj"); err != nil {
+			c.Error(err)
 			return
 		}
 		pwHash, _ := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
@@ -133,9 +327,12 @@ func SignupCompany(db *gorm.DB) gin.HandlerFunc {
 			Balance:      0,
 		}
 		if err := db.Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(service.UniqueViolation(err, "cnpj"))
 			return
 		}
+		if err := accountSvc.SendVerification(&user); err != nil {
+			log.Printf("Error issuing email verification for user %d: %v", user.ID, err)
+		}
 		// Remove password hash antes de retornar
 		user.PasswordHash = ""
 		c.JSON(http.StatusCreated, user)
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/internal/service"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NearbyRewardResponse struct {
+	RewardResponse
+	Branch     model.Branch `json:"Branch"`
+	DistanceKm float64      `json:"DistanceKm"`
+}
+
+// CompanyBranches lista as filiais da empresa; caixas usam a lista para informar onde
+// estão validando.
+func CompanyBranches(svc *service.BranchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		branches, err := svc.List(actingCompany(c))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, branches)
+	}
+}
+
+// PublicCompanyBranches mostra aos alunos onde ficam as lojas da empresa.
+func PublicCompanyBranches(svc *service.BranchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		branches, err := svc.List(id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, branches)
+	}
+}
+
+func CompanyCreateBranch(svc *service.BranchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.BranchSaveDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		branch, err := svc.Create(actingCompany(c), input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, branch)
+	}
+}
+
+func CompanyUpdateBranch(svc *service.BranchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.BranchSaveDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		branch, err := svc.Update(actingCompany(c), id, input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, branch)
+	}
+}
+
+func CompanyDeleteBranch(svc *service.BranchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.Delete(actingCompany(c), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deleted": true})
+	}
+}
+
+// queryFloat lê um número da query string; ausente é nil e inválido vira NaN, que a
+// validação do DTO recusa.
+func queryFloat(c *gin.Context, key string) *float64 {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		v = math.NaN()
+	}
+	return &v
+}
+
+// NearbyRewards busca vantagens válidas em filiais próximas: ?lat=&lng= e ?radius em km
+// (padrão dto.DefaultNearbyRadiusKm). A visibilidade segue a da listagem de vantagens.
+func NearbyRewards(svc *service.BranchService, db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		in := dto.NearbyRewardsDTO{Lat: queryFloat(c, "lat"), Lng: queryFloat(c, "lng"), Radius: dto.DefaultNearbyRadiusKm}
+		if radius := queryFloat(c, "radius"); radius != nil {
+			in.Radius = *radius
+		}
+		if errs := in.Validate(); len(errs) > 0 {
+			c.Error(errs)
+			return
+		}
+		institutionID, scoped, err := rewardAudience(c, db)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		nearby, err := svc.Nearby(*in.Lat, *in.Lng, in.Radius, institutionID, scoped)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		companies := make(map[uint]*string)
+		for _, n := range nearby {
+			if _, ok := companies[n.Reward.CompanyID]; ok {
+				continue
+			}
+			var company model.User
+			companies[n.Reward.CompanyID] = nil
+			if err := db.Select("id", "name", "company_name").First(&company, n.Reward.CompanyID).Error; err == nil {
+				name := company.Name
+				if company.CompanyName != nil {
+					name = *company.CompanyName
+				}
+				companies[n.Reward.CompanyID] = &name
+			}
+		}
+
+		baseURL := fmt.Sprintf("http://%s", c.Request.Host)
+		response := make([]NearbyRewardResponse, len(nearby))
+		for i, n := range nearby {
+			resp := NearbyRewardResponse{
+				RewardResponse: RewardResponse{Reward: n.Reward, CompanyName: companies[n.Reward.CompanyID]},
+				Branch:         n.Branch,
+				DistanceKm:     n.DistanceKm,
+			}
+			if len(n.Reward.ImageData) > 0 {
+				imageURL := fmt.Sprintf("%s/api/images/reward/%d", baseURL, n.Reward.ID)
+				resp.ImageURL = &imageURL
+			}
+			response[i] = resp
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
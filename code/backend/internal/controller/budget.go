@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListSemesters(svc *service.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		semesters, err := svc.ListSemesters()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, semesters)
+	}
+}
+
+func CreateSemester(svc *service.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.SemesterCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		semester, err := svc.CreateSemester(input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, semester)
+	}
+}
+
+func ListAllotmentPolicies(svc *service.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policies, err := svc.ListPolicies()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, policies)
+	}
+}
+
+func SaveAllotmentPolicy(svc *service.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.AllotmentPolicyCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		policy, err := svc.SavePolicy(input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, policy)
+	}
+}
+
+func MyInstitution(svc *service.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, err := svc.ForUser(c.GetUint("userID"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+func AdminInstitutionSettings(svc *service.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		settings, err := svc.Settings(id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, settings)
+	}
+}
+
+// AdminCreateInstitutionSemester cadastra um semestre no calendário próprio da instituição da rota.
+func AdminCreateInstitutionSemester(svc *service.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.SemesterCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		input.InstitutionID = &id
+		semester, err := svc.CreateSemester(input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, semester)
+	}
+}
+
+func AdminSaveInstitutionPolicy(svc *service.BudgetService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.AllotmentPolicyCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		input.InstitutionID = &id
+		policy, err := svc.SavePolicy(input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, policy)
+	}
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// classOwner restringe o acesso às turmas do professor logado; administradores (0) acessam todas.
+func classOwner(c *gin.Context) uint {
+	if c.GetString("role") == string(model.AdminRole) {
+		return 0
+	}
+	return c.GetUint("userID")
+}
+
+func AdminListCourses(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		courses, err := svc.ListCourses(optionalUint(c, "institutionId"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, courses)
+	}
+}
+
+func AdminCreateCourse(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.CourseCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		course, err := svc.CreateCourse(c.GetUint("userID"), input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, course)
+	}
+}
+
+func AdminDeleteCourse(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.DeleteCourse(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deleted": true})
+	}
+}
+
+func AdminListClasses(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		classes, err := svc.ListClasses(optionalUint(c, "courseId"), optionalUint(c, "professorId"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, classes)
+	}
+}
+
+func AdminCreateClass(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.ClassCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		class, err := svc.CreateClass(c.GetUint("userID"), input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, class)
+	}
+}
+
+func AdminDeleteClass(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.DeleteClass(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deleted": true})
+	}
+}
+
+func ProfessorClasses(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		professorID := c.GetUint("userID")
+		classes, err := svc.ListClasses(nil, &professorID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, classes)
+	}
+}
+
+func ClassRoster(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		roster, err := svc.Roster(id, classOwner(c))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, roster)
+	}
+}
+
+func AdminClassEnroll(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.EnrollmentDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		result, err := svc.Enroll(c.GetUint("userID"), id, input.StudentIDs)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func AdminClassUnenroll(svc *service.ClassService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		studentID, err := strconv.Atoi(c.Param("studentId"))
+		if err != nil || studentID <= 0 {
+			c.Error(apperror.ErrInvalidID)
+			return
+		}
+		if err := svc.Unenroll(c.GetUint("userID"), id, uint(studentID)); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"unenrolled": true})
+	}
+}
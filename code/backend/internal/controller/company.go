@@ -14,7 +14,7 @@ func CompanyProfile(svc service.CompanyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		profile, err := svc.GetProfile(c.GetUint("userID"))
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "empresa não encontrada"})
+			c.Error(service.ErrCompanyNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, profile)
@@ -25,13 +25,12 @@ func UpdateCompanyProfile(svc service.CompanyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.GetUint("userID")
 		var input dto.CompanyUpdateDTO
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
 			return
 		}
 		company, err := svc.UpdateProfile(id, input)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, company)
@@ -41,9 +40,9 @@ func UpdateCompanyProfile(svc service.CompanyService) gin.HandlerFunc {
 func CompanyStatistics(svc service.CompanyService, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.GetUint("userID")
-		stats, err := svc.GetStatistics(id, db)
+		stats, err := svc.GetStatistics(id, optionalUint(c, "institutionId"), db)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "empresa não encontrada"})
+			c.Error(service.ErrCompanyNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, stats)
@@ -52,10 +51,10 @@ func CompanyStatistics(svc service.CompanyService, db *gorm.DB) gin.HandlerFunc
 
 func CompanyValidations(svc service.CompanyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.GetUint("userID")
-		validations, err := svc.GetValidations(id)
+		limit, offset := pagination(c)
+		validations, err := svc.GetValidations(actingCompany(c), limit, offset)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, validations)
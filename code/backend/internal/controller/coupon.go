@@ -1,11 +1,12 @@
 package controller
 
 import (
+	"campuscash-backend/internal/dto"
 	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/service"
+	"campuscash-backend/pkg/codegen"
 	"campuscash-backend/pkg/mail"
-	"crypto/sha256"
-	"encoding/hex"
+	"campuscash-backend/pkg/validator"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -23,9 +24,14 @@ type CouponResponse struct {
 
 func StudentCoupons(svc service.CouponService, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		coupons, err := svc.ListStudentCoupons(c.GetUint("userID"))
+		status := model.CouponStatus(c.Query("status"))
+		if status != "" && !validCouponStatus(status) {
+			c.Error(validator.Errors{{Field: "status", Code: validator.CodeInvalid}})
+			return
+		}
+		coupons, err := svc.ListStudentCoupons(c.GetUint("userID"), status)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "não encontrado"})
+			c.Error(err)
 			return
 		}
 
@@ -68,33 +74,49 @@ func StudentRedeem(db *gorm.DB, notificationSvc *service.NotificationService) gi
 		var in struct {
 			RewardID uint `json:"reward_id" binding:"required"`
 		}
-		if err := c.ShouldBindJSON(&in); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &in) {
 			return
 		}
 
 		var rew model.Reward
 		if err := db.First(&rew, in.RewardID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "vantagem não encontrada"})
+			c.Error(service.ErrRewardNotFound)
+			return
+		}
+		if rew.SuspendedAt != nil {
+			c.Error(errRewardSuspended)
+			return
+		}
+		if err := service.EnsureRewardVisibleTo(db, rew.ID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		now := time.Now()
+		if err := service.EnsureRewardAvailable(&rew, now); err != nil {
+			c.Error(err)
 			return
 		}
 
 		var studentUser model.User
 		if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&studentUser, id).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "aluno não encontrado"})
+			c.Error(service.ErrStudentNotFound)
 			return
 		}
 		if studentUser.Balance < rew.Cost {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "saldo insuficiente"})
+			c.Error(service.ErrInsufficientBalance)
 			return
 		}
 
-		code := fmt.Sprintf("CC-%d-%d", time.Now().UnixNano(), id)
-		
-		// Gerar hash único para o cupom
-		hashInput := fmt.Sprintf("%s-%d-%d-%d", code, rew.ID, studentUser.ID, time.Now().UnixNano())
-		hashBytes := sha256.Sum256([]byte(hashInput))
-		hash := hex.EncodeToString(hashBytes[:])
+		code, err := codegen.GenerateCouponCode("CC")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		hash, err := codegen.GenerateHash()
+		if err != nil {
+			c.Error(err)
+			return
+		}
 		
 		t := model.Transaction{
 			FromUserID: &studentUser.ID,
@@ -111,23 +133,29 @@ func StudentRedeem(db *gorm.DB, notificationSvc *service.NotificationService) gi
 			Code:      code,
 			Hash:      hash,
 			Redeemed:  false,
-			CreatedAt: time.Now(),
+			CreatedAt: now,
+			ExpiresAt: service.CouponExpiry(&rew, now),
 		}
-		err := db.Transaction(func(tx *gorm.DB) error {
-			studentUser.Balance -= rew.Cost
-			if err := tx.Save(&studentUser).Error; err != nil {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := service.CheckRedemptionQuota(tx, &rew, studentUser.ID, now); err != nil {
 				return err
 			}
-			if err := tx.Create(&t).Error; err != nil {
+			if err := service.ReserveStock(tx, &rew); err != nil {
+				return err
+			}
+			if err := service.RecordTransfer(tx, &t); err != nil {
 				return err
 			}
 			if err := tx.Create(&coupon).Error; err != nil {
 				return err
 			}
+			if err := service.SignCoupon(tx, &coupon, rew.CompanyID); err != nil {
+				return err
+			}
 			return nil
 		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 
@@ -168,49 +196,29 @@ func StudentRedeem(db *gorm.DB, notificationSvc *service.NotificationService) gi
 	}
 }
 
-func CompanyValidateCoupon(svc service.CouponService, db *gorm.DB) gin.HandlerFunc {
+// CompanyValidateCoupon dá baixa no cupom apresentado no caixa. A baixa é atômica: com
+// dois caixas lendo o mesmo cupom, só um recebe sucesso.
+func CompanyValidateCoupon(svc *service.CouponValidationService, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var input struct {
-			Code string `json:"codigo"`
-			Hash string `json:"hash"`
-		}
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		
-		var coupon *model.Coupon
-		var err error
-		
-		// Buscar por hash ou código
-		if input.Hash != "" {
-			coupon, err = svc.ValidateCouponByHash(input.Hash)
-		} else if input.Code != "" {
-			coupon, err = svc.ValidateCoupon(input.Code)
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "código ou hash é obrigatório"})
-			return
-		}
-		
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "cupom não encontrado"})
+		var input dto.CouponValidateDTO
+		if !bindValid(c, &input) {
 			return
 		}
-		
-		// Se já foi usado, retornar erro
-		if coupon.Redeemed {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "cupom já foi utilizado"})
+		if input.Token == "" && input.Hash == "" && input.Code == "" {
+			c.Error(validator.Errors{{Field: "codigo", Code: validator.CodeRequired}})
 			return
 		}
-		
-		// Marcar como usado
-		if input.Hash != "" {
-			err = svc.UseCouponByHash(input.Hash)
-		} else {
-			err = svc.UseCoupon(input.Code)
-		}
+
+		coupon, validation, err := svc.Validate(input, model.CouponValidation{
+			CompanyID:   actingCompany(c),
+			ValidatedBy: c.GetUint("userID"),
+			BranchID:    input.BranchID,
+			Location:    input.Location,
+			IP:          c.ClientIP(),
+			UserAgent:   c.Request.UserAgent(),
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		
@@ -226,6 +234,7 @@ func CompanyValidateCoupon(svc service.CouponService, db *gorm.DB) gin.HandlerFu
 				Coupon: *coupon,
 				Reward: &reward,
 			},
+			"validation": validation,
 			"student": gin.H{
 				"id":   student.ID,
 				"name": student.Name,
@@ -235,17 +244,17 @@ func CompanyValidateCoupon(svc service.CouponService, db *gorm.DB) gin.HandlerFu
 }
 
 // Endpoint para buscar cupom por hash (sem marcar como usado)
-func GetCouponByHash(svc service.CouponService, db *gorm.DB) gin.HandlerFunc {
+func GetCouponByHash(svc *service.CouponValidationService, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		hash := c.Param("hash")
 		if hash == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "hash é obrigatório"})
+			c.Error(validator.Errors{{Field: "hash", Code: validator.CodeRequired}})
 			return
 		}
 		
-		coupon, err := svc.ValidateCouponByHash(hash)
+		coupon, err := svc.Find(actingCompany(c), dto.CouponValidateDTO{Hash: hash})
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "cupom não encontrado"})
+			c.Error(err)
 			return
 		}
 		
@@ -267,3 +276,122 @@ func GetCouponByHash(svc service.CouponService, db *gorm.DB) gin.HandlerFunc {
 		})
 	}
 }
+
+func validCouponStatus(status model.CouponStatus) bool {
+	switch status {
+	case model.CouponIssued, model.CouponUsed, model.CouponExpired, model.CouponCancelled, model.CouponRefunded:
+		return true
+	}
+	return false
+}
+
+func StudentCancelCoupon(svc *service.CouponLifecycleService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		coupon, err := svc.Cancel(c.GetUint("userID"), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, coupon)
+	}
+}
+
+func CompanyPublicKeys(svc *service.CouponSigningService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		keys, err := svc.PublicKeys(id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, keys)
+	}
+}
+
+func CompanyRotateKey(svc *service.CouponSigningService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := svc.RotateKey(c.GetUint("userID"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, key)
+	}
+}
+
+// CompanySyncCoupons recebe do PDV os cupons aceitos offline.
+func CompanySyncCoupons(svc *service.CouponSigningService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.CouponSyncDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		results, err := svc.Sync(model.CouponValidation{
+			CompanyID:   actingCompany(c),
+			ValidatedBy: c.GetUint("userID"),
+			BranchID:    input.BranchID,
+			Location:    input.Location,
+			IP:          c.ClientIP(),
+			UserAgent:   c.Request.UserAgent(),
+		}, input.Coupons)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"results": results})
+	}
+}
+
+// StudentCouponQR devolve o QR code do cupom: ?formato=png|svg e, no PNG, ?tamanho em pixels.
+func StudentCouponQR(svc *service.VoucherService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		format := c.DefaultQuery("formato", service.QRFormatPNG)
+		if format != service.QRFormatPNG && format != service.QRFormatSVG {
+			c.Error(validator.Errors{{Field: "formato", Code: validator.CodeInvalid}})
+			return
+		}
+		size := 256
+		if s, err := strconv.Atoi(c.Query("tamanho")); err == nil && s >= 128 && s <= 1024 {
+			size = s
+		}
+		data, err := svc.QR(c.GetUint("userID"), id, format, size)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		contentType := "image/png"
+		if format == service.QRFormatSVG {
+			contentType = "image/svg+xml"
+		}
+		c.Header("Cache-Control", "private, no-store")
+		c.Data(http.StatusOK, contentType, data)
+	}
+}
+
+func StudentCouponVoucher(svc *service.VoucherService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		data, err := svc.Voucher(c.GetUint("userID"), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Header("Cache-Control", "private, no-store")
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="voucher-%d.pdf"`, id))
+		c.Data(http.StatusOK, "application/pdf", data)
+	}
+}
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/i18n"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Erros verificados direto nos handlers; os demais vêm dos services.
+var (
+	errInvalidCredentials = apperror.New("invalid_credentials", http.StatusUnauthorized)
+	errAccountPending     = apperror.New("account_pending", http.StatusForbidden)
+	errAccountDisabled    = apperror.New("account_disabled", http.StatusForbidden)
+	errEmailNotVerified   = apperror.New("email_not_verified", http.StatusForbidden)
+	errFileTooLarge       = apperror.New("file_too_large", http.StatusBadRequest)
+	errInvalidImage       = apperror.New("invalid_image", http.StatusBadRequest)
+	errInvalidImageType   = apperror.New("invalid_image_type", http.StatusBadRequest)
+	errRewardSuspended    = apperror.New("reward_suspended", http.StatusBadRequest)
+	errImageNotFound      = apperror.New("image_not_found", http.StatusNotFound)
+)
+
+// requestLang é o idioma pedido pelo cliente, para textos montados fora do ErrorHandler.
+func requestLang(c *gin.Context) i18n.Lang {
+	return i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+}
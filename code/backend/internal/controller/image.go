@@ -16,20 +16,19 @@ func UploadStudentAvatar(db *gorm.DB, imgSvc *service.ImageService) gin.HandlerF
 
 		imgData, err := imgSvc.ProcessImage(c, "avatar")
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process image: " + err.Error()})
+			c.Error(errInvalidImage.Wrap(err))
 			return
 		}
 
 
 		var user model.User
 		if err := db.First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			c.Error(service.ErrUserNotFound)
 			return
 		}
 
-		user.AvatarData = imgData
-		if err := db.Save(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+		if err := db.Model(&user).Update("AvatarData", imgData).Error; err != nil {
+			c.Error(err)
 			return
 		}
 
@@ -47,20 +46,20 @@ func UploadCompanyLogo(db *gorm.DB, imgSvc *service.ImageService) gin.HandlerFun
 
 		imgData, err := imgSvc.ProcessImage(c, "logo")
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process image: " + err.Error()})
+			c.Error(errInvalidImage.Wrap(err))
 			return
 		}
 
 
 		var user model.User
 		if err := db.First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			c.Error(service.ErrUserNotFound)
 			return
 		}
 
-		user.AvatarData = imgData // Using AvatarData field for company logo too
-		if err := db.Save(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save logo"})
+		// Using AvatarData field for company logo too
+		if err := db.Model(&user).Update("AvatarData", imgData).Error; err != nil {
+			c.Error(err)
 			return
 		}
 
@@ -73,32 +72,32 @@ func UploadCompanyLogo(db *gorm.DB, imgSvc *service.ImageService) gin.HandlerFun
 
 func UploadRewardImage(db *gorm.DB, imgSvc *service.ImageService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		companyID := c.GetUint("userID")
+		companyID := actingCompany(c)
 		rewardID := c.Param("id")
 		
 
 		imgData, err := imgSvc.ProcessImage(c, "image")
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to process image: " + err.Error()})
+			c.Error(errInvalidImage.Wrap(err))
 			return
 		}
 
 
 		var reward model.Reward
 		if err := db.First(&reward, rewardID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+			c.Error(service.ErrRewardNotFound)
 			return
 		}
 
 
 		if reward.CompanyID != companyID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to update this reward"})
+			c.Error(service.ErrNotRewardOwner)
 			return
 		}
 
 		// Atualizar apenas o campo ImageData
 		if err := db.Model(&reward).Update("ImageData", imgData).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image: " + err.Error()})
+			c.Error(err)
 			return
 		}
 
@@ -121,7 +120,7 @@ func GetImage(db *gorm.DB) gin.HandlerFunc {
 		case "avatar":
 			var user model.User
 			if err := db.First(&user, imageID).Error; err != nil {
-				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				c.Error(service.ErrUserNotFound)
 				return
 			}
 			imgData = user.AvatarData
@@ -130,7 +129,7 @@ func GetImage(db *gorm.DB) gin.HandlerFunc {
 		case "reward":
 			var reward model.Reward
 			if err := db.First(&reward, imageID).Error; err != nil {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+				c.Error(service.ErrRewardNotFound)
 				return
 			}
 			imgData = reward.ImageData
@@ -139,12 +138,12 @@ func GetImage(db *gorm.DB) gin.HandlerFunc {
 			contentType = imgSvc.GetImageContentType(imgData)
 			
 		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image type"})
+			c.Error(errInvalidImageType)
 			return
 		}
 
 		if len(imgData) == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "No image found"})
+			c.Error(errImageNotFound)
 			return
 		}
 
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/i18n"
+	"campuscash-backend/internal/service"
+	"campuscash-backend/pkg/validator"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tamanho máximo do CSV de importação de professores
+const maxImportSize = 1 << 20
+
+func AdminInviteProfessor(svc *service.InvitationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.ProfessorInviteDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		user, err := svc.InviteProfessor(c.GetUint("userID"), input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, user)
+	}
+}
+
+func AdminImportProfessors(svc *service.InvitationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		institutionID, err := strconv.Atoi(c.PostForm("institutionId"))
+		if err != nil || institutionID <= 0 {
+			c.Error(validator.Errors{{Field: "institutionId", Code: validator.CodeRequired}})
+			return
+		}
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.Error(validator.Errors{{Field: "file", Code: validator.CodeRequired}})
+			return
+		}
+		if header.Size > maxImportSize {
+			c.Error(errFileTooLarge.With("maxBytes", maxImportSize))
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			c.Error(apperror.ErrInvalidBody.Wrap(err))
+			return
+		}
+		defer file.Close()
+
+		report, err := svc.ImportProfessors(c.GetUint("userID"), uint(institutionID), file)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		lang := requestLang(c)
+		for i, row := range report.Rows {
+			if row.Code != "" {
+				report.Rows[i].Error = i18n.Message(lang, row.Code, row.Details)
+			}
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func AdminResendInvitation(svc *service.InvitationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.ResendInvitation(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"sent": true})
+	}
+}
+
+func ProfessorInvitation(svc *service.InvitationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info, err := svc.Lookup(c.Query("token"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, info)
+	}
+}
+
+func ActivateProfessor(svc *service.InvitationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.AccountActivationDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := svc.Activate(input.Token, input.Password); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"activated": true})
+	}
+}
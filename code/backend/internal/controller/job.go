@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"campuscash-backend/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListJobs(scheduler *service.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobs, err := scheduler.Jobs()
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, jobs)
+	}
+}
+
+func JobHistory(scheduler *service.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := 50
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 500 {
+				limit = l
+			}
+		}
+		runs, err := scheduler.History(c.Param("name"), limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, runs)
+	}
+}
+
+func TriggerJob(scheduler *service.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		run, err := scheduler.Trigger(c.Param("name"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, run)
+	}
+}
+
+func AdminTriggerJob(scheduler *service.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		run, err := scheduler.TriggerAs(c.GetUint("userID"), c.Param("name"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, run)
+	}
+}
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"campuscash-backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func LedgerStatement(svc *service.LedgerService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		statement, err := svc.Statement(c.GetUint("userID"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, statement)
+	}
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func LoginMFA(sessionSvc *service.SessionService, mfaSvc *service.MFAService, guard *service.LoginGuard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.MFALoginDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		owner, err := mfaSvc.ChallengeUser(input.ChallengeToken)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		// Códigos errados contam como falha de login: sem isso, quem já tem a senha
+		// poderia pedir desafios novos indefinidamente para adivinhar o código
+		if err := guard.Check(owner.Email, c.ClientIP()); err != nil {
+			loginBlocked(c, err)
+			return
+		}
+		user, recoveryCodes, err := mfaSvc.CompleteChallenge(input.ChallengeToken, input.Code)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidMFACode) {
+				guard.Fail(owner.Email, c.ClientIP())
+			}
+			c.Error(err)
+			return
+		}
+		guard.Succeed(user.Email)
+		var extra gin.H
+		if recoveryCodes != nil {
+			extra = gin.H{"recoveryCodes": recoveryCodes}
+		}
+		startSession(c, sessionSvc, user, extra)
+	}
+}
+
+// LoginMFASetup atende quem precisa cadastrar o segundo fator para conseguir entrar.
+func LoginMFASetup(mfaSvc *service.MFAService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.MFAChallengeTokenDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		enrollment, err := mfaSvc.ChallengeEnrollment(input.ChallengeToken)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, enrollment)
+	}
+}
+
+func MFAStatus(mfaSvc *service.MFAService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := mfaSvc.Status(c.GetUint("userID"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, status)
+	}
+}
+
+func MFAEnroll(mfaSvc *service.MFAService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		enrollment, err := mfaSvc.BeginEnrollment(c.GetUint("userID"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, enrollment)
+	}
+}
+
+func MFAConfirm(mfaSvc *service.MFAService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.MFACodeDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		codes, err := mfaSvc.ConfirmEnrollment(c.GetUint("userID"), input.Code)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, codes)
+	}
+}
+
+func MFARegenerateRecoveryCodes(mfaSvc *service.MFAService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.MFACodeDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		codes, err := mfaSvc.RegenerateRecoveryCodes(c.GetUint("userID"), input.Code)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, codes)
+	}
+}
+
+func MFADisable(mfaSvc *service.MFAService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.MFADisableDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		if err := mfaSvc.Disable(c.GetUint("userID"), input.Password, input.Code); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+	}
+}
+
+func AdminResetMFA(svc *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.ResetMFA(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"reset": true})
+	}
+}
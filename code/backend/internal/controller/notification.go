@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"campuscash-backend/internal/apperror"
 	"campuscash-backend/internal/service"
 	"net/http"
 	"strconv"
@@ -13,7 +14,7 @@ func ListNotifications(svc *service.NotificationService) gin.HandlerFunc {
 		userID := c.GetUint("userID")
 		notifications, err := svc.ListUserNotifications(userID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, notifications)
@@ -26,11 +27,11 @@ func MarkNotificationAsRead(svc *service.NotificationService) gin.HandlerFunc {
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+			c.Error(apperror.ErrInvalidID)
 			return
 		}
 		if err := svc.MarkAsRead(uint(id), userID); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"success": true})
@@ -42,7 +43,7 @@ func CountUnreadNotifications(svc *service.NotificationService) gin.HandlerFunc
 		userID := c.GetUint("userID")
 		count, err := svc.CountUnread(userID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"count": count})
@@ -53,7 +54,7 @@ func MarkAllNotificationsAsRead(svc *service.NotificationService) gin.HandlerFun
 	return func(c *gin.Context) {
 		userID := c.GetUint("userID")
 		if err := svc.MarkAllAsRead(userID); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"success": true})
@@ -13,7 +13,7 @@ func ProfessorProfile(svc service.ProfessorService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		prof, err := svc.GetProfile(c.GetUint("userID"))
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "professor não encontrado"})
+			c.Error(service.ErrProfessorNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, prof)
@@ -24,7 +24,7 @@ func ProfessorBalance(svc service.ProfessorService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		balance, err := svc.GetBalance(c.GetUint("userID"))
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "não encontrado"})
+			c.Error(service.ErrProfessorNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"saldoMoedas": balance})
@@ -50,13 +50,12 @@ func UpdateProfessorProfile(svc service.ProfessorService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.GetUint("userID")
 		var input dto.ProfessorUpdateDTO
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
 			return
 		}
 		professor, err := svc.UpdateProfile(id, input)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, professor)
@@ -68,7 +67,7 @@ func ProfessorStatistics(svc service.ProfessorService) gin.HandlerFunc {
 		id := c.GetUint("userID")
 		stats, err := svc.GetStatistics(id)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "professor não encontrado"})
+			c.Error(service.ErrProfessorNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, stats)
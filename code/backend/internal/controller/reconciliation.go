@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"campuscash-backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ReconciliationReport(svc *service.ReconciliationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := svc.Run(false)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func ReconciliationRepair(svc *service.ReconciliationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := svc.Run(true)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"campuscash-backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReversalInput struct {
+	Reason string `json:"motivo" binding:"required"`
+}
+
+func ProfessorReverseTransaction(svc *service.ReversalService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input ReversalInput
+		if !bindValid(c, &input) {
+			return
+		}
+		reversal, err := svc.ReverseGive(c.GetUint("userID"), id, input.Reason)
+		if err != nil {
+			if errors.Is(err, service.ErrInsufficientBalance) {
+				err = service.ErrCoinsSpent
+			}
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, reversal)
+	}
+}
+
+func CompanyRefundRedemption(svc *service.ReversalService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input ReversalInput
+		if !bindValid(c, &input) {
+			return
+		}
+		refund, err := svc.RefundRedemption(c.GetUint("userID"), id, input.Reason)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, refund)
+	}
+}
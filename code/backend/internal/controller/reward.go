@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"campuscash-backend/internal/apperror"
 	"campuscash-backend/internal/dto"
 	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/service"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -18,19 +20,37 @@ type RewardResponse struct {
 	CompanyName  *string `json:"CompanyName,omitempty"`
 	ImageURL     *string `json:"ImageURL,omitempty"`
 	ResgatesCount int    `json:"ResgatesCount,omitempty"`
+	InstitutionIDs []uint `json:"InstitutionIDs,omitempty"`
+	BranchIDs      []uint `json:"BranchIDs,omitempty"`
+	// Onde o cupom pode ser usado, no detalhe da vantagem
+	Branches       []model.Branch `json:"Branches,omitempty"`
+}
+
+// rewardAudience define quais vantagens o visitante enxerga. Alunos e professores ficam
+// na própria instituição; os demais podem filtrar por "instituicao". Anônimos sem filtro
+// veem só as vantagens abertas a todas; empresas e administradores sem filtro veem tudo.
+func rewardAudience(c *gin.Context, db *gorm.DB) (*uint, bool, error) {
+	role := c.GetString("role")
+	if role == string(model.StudentRole) || role == string(model.ProfessorRole) {
+		institutionID, err := service.TenantOf(db, c.GetUint("userID"))
+		return institutionID, true, err
+	}
+	if institutionID := optionalUint(c, "instituicao"); institutionID != nil {
+		return institutionID, true, nil
+	}
+	return nil, role == "", nil
 }
 
 func CompanyCreateReward(svc service.RewardService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input dto.RewardCreateDTO
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
 			return
 		}
-		input.CompanyID = c.GetUint("userID")
+		input.CompanyID = actingCompany(c)
 		reward, err := svc.CreateReward(input)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, reward)
@@ -39,9 +59,9 @@ func CompanyCreateReward(svc service.RewardService) gin.HandlerFunc {
 
 func CompanyRewards(svc service.RewardService, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		rewards, err := svc.ListCompanyRewards(c.GetUint("userID"))
+		rewards, err := svc.ListCompanyRewards(actingCompany(c))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		
@@ -80,6 +100,17 @@ func CompanyRewards(svc service.RewardService, db *gorm.DB) gin.HandlerFunc {
 		for _, count := range counts {
 			countMap[count.RewardID] = int(count.Count)
 		}
+
+		institutions, err := service.RewardInstitutionIDs(db, rewardIDs)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		branches, err := service.RewardBranchIDs(db, rewardIDs)
+		if err != nil {
+			c.Error(err)
+			return
+		}
 		
 		// Converter para RewardResponse com ImageURL e contagem
 		baseURL := fmt.Sprintf("http://%s", c.Request.Host)
@@ -91,6 +122,8 @@ func CompanyRewards(svc service.RewardService, db *gorm.DB) gin.HandlerFunc {
 				resp.ImageURL = &imageURL
 			}
 			resp.ResgatesCount = countMap[reward.ID]
+			resp.InstitutionIDs = institutions[reward.ID]
+			resp.BranchIDs = branches[reward.ID]
 			response[i] = resp
 		}
 		
@@ -100,34 +133,58 @@ func CompanyRewards(svc service.RewardService, db *gorm.DB) gin.HandlerFunc {
 
 func CompanyUpdateReward(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		companyID := c.GetUint("userID")
+		companyID := actingCompany(c)
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID da vantagem inválido"})
+			c.Error(apperror.ErrInvalidID)
 			return
 		}
 		var in dto.RewardCreateDTO
-		if err := c.ShouldBindJSON(&in); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &in) {
 			return
 		}
 		var rew model.Reward
 		if err := db.First(&rew, uint(id)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "vantagem não encontrada"})
+			c.Error(service.ErrRewardNotFound)
 			return
 		}
 		if rew.CompanyID != companyID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "não é o proprietário"})
+			c.Error(service.ErrNotRewardOwner)
 			return
 		}
 		rew.Title = in.Title
 		rew.Description = in.Description
 		rew.Cost = in.Cost
 		rew.Category = in.Category
-		rew.Active = true
-		if err := db.Save(&rew).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		rew.MaxPerStudent = in.MaxPerStudent
+		rew.PeriodLimit = in.PeriodLimit
+		rew.PeriodDays = in.PeriodDays
+		if !sameTime(rew.PublishAt, in.PublishAt) {
+			rew.LaunchNotifiedAt = nil
+		}
+		rew.PublishAt = in.PublishAt
+		rew.ExpireAt = in.ExpireAt
+		rew.CouponValidDays = in.CouponValidDays
+		rew.RefundOnExpiry = in.RefundOnExpiry
+		// Vantagem esgotada só volta ao catálogo com reposição de estoque
+		rew.Active = rew.SoldOutAt == nil
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&rew).Error; err != nil {
+				return err
+			}
+			if in.InstitutionIDs != nil {
+				if err := service.SetRewardInstitutions(tx, rew.ID, in.InstitutionIDs); err != nil {
+					return err
+				}
+			}
+			if in.BranchIDs == nil {
+				return nil
+			}
+			return service.SetRewardBranches(tx, companyID, rew.ID, in.BranchIDs)
+		})
+		if err != nil {
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, rew)
@@ -136,25 +193,29 @@ func CompanyUpdateReward(db *gorm.DB) gin.HandlerFunc {
 
 func CompanyUpdateRewardStatus(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		companyID := c.GetUint("userID")
+		companyID := actingCompany(c)
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID da vantagem inválido"})
+			c.Error(apperror.ErrInvalidID)
 			return
 		}
 		var rew model.Reward
 		if err := db.First(&rew, uint(id)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "vantagem não encontrada"})
+			c.Error(service.ErrRewardNotFound)
 			return
 		}
 		if rew.CompanyID != companyID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "não é o proprietário"})
+			c.Error(service.ErrNotRewardOwner)
+			return
+		}
+		if !rew.Active && rew.SoldOutAt != nil {
+			c.Error(service.ErrRewardSoldOut)
 			return
 		}
 		rew.Active = !rew.Active
 		if err := db.Save(&rew).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"active": rew.Active})
@@ -163,24 +224,24 @@ func CompanyUpdateRewardStatus(db *gorm.DB) gin.HandlerFunc {
 
 func CompanyDeleteReward(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		companyID := c.GetUint("userID")
+		companyID := actingCompany(c)
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID da vantagem inválido"})
+			c.Error(apperror.ErrInvalidID)
 			return
 		}
 		var rew model.Reward
 		if err := db.First(&rew, uint(id)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "vantagem não encontrada"})
+			c.Error(service.ErrRewardNotFound)
 			return
 		}
 		if rew.CompanyID != companyID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "não é o proprietário"})
+			c.Error(service.ErrNotRewardOwner)
 			return
 		}
 		if err := db.Delete(&rew).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"deleted": true})
@@ -189,8 +250,22 @@ func CompanyDeleteReward(db *gorm.DB) gin.HandlerFunc {
 
 func ListRewards(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		institutionID, scoped, err := rewardAudience(c, db)
+		if err != nil {
+			c.Error(err)
+			return
+		}
 		var rewards []model.Reward
-		query := db.Where("active = ?", true)
+		query := db.Where("active = ? AND suspended_at IS NULL", true)
+		// "em_breve" lista as promoções agendadas, que os alunos podem favoritar
+		if c.Query("disponibilidade") == "em_breve" {
+			query = query.Scopes(service.RewardsUpcomingAt(time.Now()))
+		} else {
+			query = query.Scopes(service.RewardsAvailableAt(time.Now()))
+		}
+		if scoped {
+			query = query.Scopes(service.RewardsVisibleTo(institutionID))
+		}
 
 		if categoria := c.Query("categoria"); categoria != "" && categoria != "todas" {
 			query = query.Where("category = ?", categoria)
@@ -271,23 +346,49 @@ func GetRewardById(db *gorm.DB) gin.HandlerFunc {
 		idStr := c.Param("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ID da vantagem inválido"})
+			c.Error(apperror.ErrInvalidID)
 			return
 		}
 		var reward model.Reward
 		if err := db.First(&reward, uint(id)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "vantagem não encontrada"})
+			c.Error(service.ErrRewardNotFound)
 			return
 		}
-		if !reward.Active {
-			c.JSON(http.StatusNotFound, gin.H{"error": "vantagem não encontrada"})
+		if (!reward.Active && reward.SoldOutAt == nil) || reward.SuspendedAt != nil {
+			c.Error(service.ErrRewardNotFound)
+			return
+		}
+		if reward.ExpireAt != nil && !time.Now().Before(*reward.ExpireAt) {
+			c.Error(service.ErrRewardNotFound)
+			return
+		}
+		institutionID, scoped, err := rewardAudience(c, db)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if scoped {
+			visible, err := service.RewardVisible(db, reward.ID, institutionID)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			if !visible {
+				c.Error(service.ErrRewardNotFound)
+				return
+			}
+		}
+
+		branches, err := service.RewardBranches(db, &reward)
+		if err != nil {
+			c.Error(err)
 			return
 		}
 
 		// Buscar dados da empresa
 		var company model.User
 		if err := db.Where("id = ? AND role = ?", reward.CompanyID, model.CompanyRole).First(&company).Error; err == nil {
-			resp := RewardResponse{Reward: reward}
+			resp := RewardResponse{Reward: reward, Branches: branches}
 			if company.CompanyName != nil {
 				resp.CompanyName = company.CompanyName
 			} else {
@@ -302,7 +403,7 @@ func GetRewardById(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusOK, resp)
 		} else {
 			// Se não encontrar empresa, retornar reward sem dados da empresa
-			resp := RewardResponse{Reward: reward}
+			resp := RewardResponse{Reward: reward, Branches: branches}
 			if len(reward.ImageData) > 0 {
 				baseURL := fmt.Sprintf("http://%s", c.Request.Host)
 				imageURL := fmt.Sprintf("%s/api/images/reward/%d", baseURL, reward.ID)
@@ -312,3 +413,99 @@ func GetRewardById(db *gorm.DB) gin.HandlerFunc {
 		}
 	}
 }
+
+// CompanySetRewardStock repõe o estoque; "estoque": null volta a vantagem para ilimitada.
+func CompanySetRewardStock(svc *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var in dto.RewardStockDTO
+		if !bindValid(c, &in) {
+			return
+		}
+		reward, err := svc.SetStock(actingCompany(c), id, in.Stock)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, reward)
+	}
+}
+
+func JoinRewardWaitlist(svc *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		entry, err := svc.JoinWaitlist(c.GetUint("userID"), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+func LeaveRewardWaitlist(svc *service.InventoryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.LeaveWaitlist(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deleted": true})
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func StudentFavorites(svc *service.RewardLaunchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rewards, err := svc.Favorites(c.GetUint("userID"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, rewards)
+	}
+}
+
+func AddRewardFavorite(svc *service.RewardLaunchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		favorite, err := svc.AddFavorite(c.GetUint("userID"), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, favorite)
+	}
+}
+
+func RemoveRewardFavorite(svc *service.RewardLaunchService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		if err := svc.RemoveFavorite(c.GetUint("userID"), id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"deleted": true})
+	}
+}
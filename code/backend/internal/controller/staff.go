@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actingCompany é a empresa em nome da qual a requisição age: a própria empresa logada ou
+// a empresa do funcionário. O usuário (userID) continua sendo quem fez a operação.
+func actingCompany(c *gin.Context) uint {
+	return c.GetUint("companyID")
+}
+
+func CompanyListStaff(svc *service.StaffService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		staff, err := svc.List(actingCompany(c))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, staff)
+	}
+}
+
+func CompanyCreateStaff(svc *service.StaffService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input dto.StaffCreateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		staff, err := svc.Create(actingCompany(c), input)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, staff)
+	}
+}
+
+func CompanyChangeStaffRole(svc *service.StaffService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		var input dto.StaffRoleUpdateDTO
+		if !bindValid(c, &input) {
+			return
+		}
+		staff, err := svc.ChangeRole(actingCompany(c), id, input.Role)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, staff)
+	}
+}
+
+func CompanySetStaffActive(svc *service.StaffService, active bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := paramID(c)
+		if !ok {
+			return
+		}
+		staff, err := svc.SetActive(actingCompany(c), id, active)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, staff)
+	}
+}
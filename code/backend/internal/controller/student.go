@@ -4,6 +4,7 @@ import (
 	"campuscash-backend/internal/dto"
 	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/service"
+	"campuscash-backend/pkg/validator"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,13 +14,12 @@ import (
 func RegisterStudent(svc service.StudentService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input dto.StudentRegisterDTO
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
 			return
 		}
 		student, err := svc.RegisterStudent(input)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"id": student.ID})
@@ -31,7 +31,7 @@ func StudentProfile(svc service.StudentService) gin.HandlerFunc {
 		id := c.GetUint("userID")
 		prof, err := svc.GetProfile(id)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "aluno não encontrado"})
+			c.Error(service.ErrStudentNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, prof)
@@ -43,7 +43,7 @@ func StudentBalance(svc service.StudentService) gin.HandlerFunc {
 		id := c.GetUint("userID")
 		balance, err := svc.GetBalance(id)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "aluno não encontrado"})
+			c.Error(service.ErrStudentNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, balance)
@@ -54,13 +54,12 @@ func UpdateStudentProfile(svc service.StudentService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.GetUint("userID")
 		var input dto.StudentUpdateDTO
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
 			return
 		}
 		student, err := svc.UpdateProfile(id, input)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, student)
@@ -72,23 +71,24 @@ func StudentStatistics(svc service.StudentService) gin.HandlerFunc {
 		id := c.GetUint("userID")
 		stats, err := svc.GetStatistics(id)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "aluno não encontrado"})
+			c.Error(service.ErrStudentNotFound)
 			return
 		}
 		c.JSON(http.StatusOK, stats)
 	}
 }
 
-func SearchStudents(svc service.StudentService) gin.HandlerFunc {
+// SearchStudents busca entre os alunos que o professor pode premiar.
+func SearchStudents(svc service.ProfessorService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		query := c.Query("q")
 		if query == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "parâmetro de consulta 'q' é obrigatório"})
+			c.Error(validator.Errors{{Field: "q", Code: validator.CodeRequired}})
 			return
 		}
-		students, err := svc.SearchStudents(query)
+		students, err := svc.SearchStudents(c.GetUint("userID"), query)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		c.JSON(http.StatusOK, students)
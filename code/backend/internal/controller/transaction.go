@@ -21,15 +21,14 @@ type GiveCoinsInput struct {
 func GiveCoins(db *gorm.DB, notificationSvc *service.NotificationService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input GiveCoinsInput
-		if err := c.ShouldBindJSON(&input); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !bindValid(c, &input) {
 			return
 		}
 		professorID := c.GetUint("userID")
 		
 		// Usar SendCoins do service para evitar duplicação de lógica
 		if err := service.SendCoins(db, professorID, input.ToStudentID, input.Amount, input.Message); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 		
@@ -39,7 +38,7 @@ func GiveCoins(db *gorm.DB, notificationSvc *service.NotificationService) gin.Ha
 				input.ToStudentID,
 				model.NotificationTypeReceiveCoins,
 				"Moedas Recebidas",
-				fmt.Sprintf("Você recebeu %d moedas: %s", input.Amount, input.Message),
+				fmt.Sprintf("Você recebeu %d %s: %s", input.Amount, service.CoinNameFor(db, input.ToStudentID), input.Message),
 			)
 		}()
 		
@@ -57,6 +56,7 @@ type TransactionResponse struct {
 	RewardID    *uint           `json:"RewardID"`
 	CreatedAt   time.Time       `json:"CreatedAt"`
 	Code        *string         `json:"Code"`
+	ReversalOfID *uint          `json:"ReversalOfID,omitempty"`
 	FromUserName *string        `json:"FromUserName,omitempty"`
 	ToUserName   *string        `json:"ToUserName,omitempty"`
 	RewardTitle  *string        `json:"RewardTitle,omitempty"`
@@ -165,6 +165,7 @@ func StudentTransactions(db *gorm.DB) gin.HandlerFunc {
 				RewardID:   tx.RewardID,
 				CreatedAt:  tx.CreatedAt,
 				Code:       tx.Code,
+				ReversalOfID: tx.ReversalOfID,
 			}
 			if tx.FromUserID != nil {
 				if u, ok := users[*tx.FromUserID]; ok {
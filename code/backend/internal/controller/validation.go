@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/pkg/validator"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	playground "github.com/go-playground/validator/v10"
+)
+
+// Os erros do binding passam a usar o nome do campo no JSON ("cpf") em vez do nome Go ("CPF").
+func init() {
+	if v, ok := binding.Validator.Engine().(*playground.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// Validatable é implementado pelas entradas que normalizam e validam os próprios campos.
+type Validatable interface {
+	Validate() validator.Errors
+}
+
+func bindingCode(tag string) string {
+	switch tag {
+	case "required":
+		return validator.CodeRequired
+	case "min":
+		return validator.CodeTooShort
+	case "max":
+		return validator.CodeTooLong
+	}
+	return validator.CodeInvalid
+}
+
+// bindValid faz o bind do JSON e, se a entrada for Validatable, roda a validação.
+// Em caso de erro registra a lista de campos para o ErrorHandler e devolve false.
+func bindValid(c *gin.Context, input interface{}) bool {
+	if err := c.ShouldBindJSON(input); err != nil {
+		var bindErrs playground.ValidationErrors
+		if !errors.As(err, &bindErrs) {
+			c.Error(apperror.ErrInvalidBody.Wrap(err))
+			return false
+		}
+		var errs validator.Errors
+		for _, fe := range bindErrs {
+			errs.Add(fe.Field(), bindingCode(fe.Tag()), "")
+		}
+		c.Error(errs)
+		return false
+	}
+	if v, ok := input.(Validatable); ok {
+		if errs := v.Validate(); len(errs) > 0 {
+			c.Error(errs)
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AdminUserDTO struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	Balance       uint      `json:"balance"`
+	InstitutionID *uint     `json:"institutionId,omitempty"`
+	Department    *string   `json:"department,omitempty"`
+	CompanyName   *string   `json:"companyName,omitempty"`
+	Active        bool      `json:"active"`
+	Pending       bool      `json:"pending"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type AdminUserListDTO struct {
+	Total int64          `json:"total"`
+	Users []AdminUserDTO `json:"users"`
+}
+
+type AdminUserCreateDTO struct {
+	Name          string  `json:"name" binding:"required"`
+	Email         string  `json:"email" binding:"required,email"`
+	Password      string  `json:"password" binding:"required,min=6"`
+	Role          string  `json:"role" binding:"required"`
+	InstitutionID *uint   `json:"institutionId"`
+	Department    *string `json:"department"`
+}
+
+type AdminRoleUpdateDTO struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type AdminPasswordResetDTO struct {
+	TemporaryPassword string `json:"temporaryPassword"`
+}
+
+type InstitutionSaveDTO struct {
+	Name                      string `json:"name" binding:"required"`
+	CoinExpiryDays            uint   `json:"coinExpiryDays"`
+	AllowUnenrolledRecipients bool   `json:"allowUnenrolledRecipients"`
+	CoinName                  string `json:"coinName"`
+}
+
+type RewardModerationDTO struct {
+	Reason string `json:"motivo" binding:"required"`
+}
+
+type LedgerAccountDTO struct {
+	ID      uint   `json:"id"`
+	Kind    string `json:"kind"`
+	UserID  *uint  `json:"userId,omitempty"`
+	Name    string `json:"name"`
+	Balance int64  `json:"balance"`
+}
+
+type JournalEntryDTO struct {
+	ID            uint                `json:"id"`
+	Type          string              `json:"type"`
+	TransactionID *uint               `json:"transactionId,omitempty"`
+	Memo          string              `json:"memo"`
+	Postings      []JournalPostingDTO `json:"postings"`
+	CreatedAt     time.Time           `json:"createdAt"`
+}
+
+type JournalPostingDTO struct {
+	AccountID uint  `json:"accountId"`
+	Amount    int64 `json:"amount"`
+}
+
+type AuditLogDTO struct {
+	ID         uint            `json:"id"`
+	ActorID    uint            `json:"actorId"`
+	ActorName  string          `json:"actorName,omitempty"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType"`
+	TargetID   *uint           `json:"targetId,omitempty"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
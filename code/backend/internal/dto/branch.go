@@ -0,0 +1,31 @@
+package dto
+
+type BranchSaveDTO struct {
+	Name         string   `json:"nome"`
+	Address      string   `json:"endereco"`
+	Latitude     *float64 `json:"latitude"`
+	Longitude    *float64 `json:"longitude"`
+	OpeningHours string   `json:"horarioFuncionamento"`
+}
+
+// Raio da busca de vantagens próximas, em km.
+const (
+	DefaultNearbyRadiusKm = 5
+	MaxNearbyRadiusKm     = 50
+)
+
+// NearbyRewardsDTO são os parâmetros de /api/rewards/nearby; coordenadas que não
+// puderam ser lidas chegam como NaN e são recusadas na validação.
+type NearbyRewardsDTO struct {
+	Lat    *float64
+	Lng    *float64
+	Radius float64
+}
+
+type BranchStatisticsDTO struct {
+	BranchID         uint   `json:"filialId"`
+	Name             string `json:"nome"`
+	Validations      uint   `json:"validacoes"`
+	ValidationsMonth uint   `json:"validacoesMes"`
+	Coins            uint   `json:"moedas"`
+}
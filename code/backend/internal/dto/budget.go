@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type SemesterCreateDTO struct {
+	InstitutionID *uint     `json:"institutionId"`
+	Name          string    `json:"name" binding:"required"`
+	StartsAt      time.Time `json:"startsAt" binding:"required"`
+	EndsAt        time.Time `json:"endsAt" binding:"required"`
+}
+
+type AllotmentPolicyCreateDTO struct {
+	InstitutionID *uint   `json:"institutionId"`
+	Department    *string `json:"department"`
+	Amount        uint    `json:"amount" binding:"required"`
+	Accumulate    bool    `json:"accumulate"`
+}
+
+type AllotmentRunDTO struct {
+	RanAt    time.Time `json:"ranAt"`
+	Credited int       `json:"credited"`
+	Skipped  int       `json:"skipped"`
+	Failed   int       `json:"failed"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+type CourseCreateDTO struct {
+	InstitutionID uint   `json:"institutionId" binding:"required"`
+	Code          string `json:"code" binding:"required"`
+	Name          string `json:"name" binding:"required"`
+}
+
+type ClassCreateDTO struct {
+	CourseID    uint   `json:"courseId" binding:"required"`
+	ProfessorID uint   `json:"professorId" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+}
+
+type ClassDTO struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	CourseID      uint      `json:"courseId"`
+	CourseCode    string    `json:"courseCode"`
+	CourseName    string    `json:"courseName"`
+	InstitutionID uint      `json:"institutionId"`
+	ProfessorID   uint      `json:"professorId"`
+	ProfessorName string    `json:"professorName"`
+	Students      int64     `json:"students"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type EnrollmentDTO struct {
+	StudentIDs []uint `json:"studentIds" binding:"required,min=1"`
+}
+
+type RosterStudentDTO struct {
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Registration *string   `json:"registration,omitempty"`
+	Course       *string   `json:"course,omitempty"`
+	EnrolledAt   time.Time `json:"enrolledAt"`
+}
+
+type EnrollmentResultDTO struct {
+	Enrolled int `json:"enrolled"`
+	// Alunos que já estavam na turma
+	Skipped int `json:"skipped"`
+}
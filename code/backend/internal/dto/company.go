@@ -20,7 +20,7 @@ type CompanyProfileDTO struct {
 
 type CompanyUpdateDTO struct {
     Name     string `json:"nomeFantasia"`
-    Email    string `json:"email" binding:"email"`
+    Email    string `json:"email"`
     CNPJ     string `json:"cnpj"`
     Address  string `json:"endereco"`
     Phone    string `json:"telefone"`
@@ -38,6 +38,8 @@ type CompanyStatisticsDTO struct {
     ResgatesMesPercentual        float64 `json:"resgatesMesPercentual,omitempty"`
     ReceitaMoedasPercentual      float64 `json:"receitaMoedasPercentual,omitempty"`
     AlunosUnicosPercentual       float64 `json:"alunosUnicosPercentual,omitempty"`
+    // Cupons validados em cada filial
+    Filiais                      []BranchStatisticsDTO `json:"filiais"`
 }
 
 type CompanyValidationDTO struct {
@@ -48,4 +50,11 @@ type CompanyValidationDTO struct {
     Data      string `json:"data"`
     Status    string `json:"status"`
     Valor     uint   `json:"valor"`
+    // Quem deu baixa (a empresa ou um funcionário), por qual canal e onde
+    ValidadoPorID uint   `json:"validadoPorId"`
+    ValidadoPor   string `json:"validadoPor"`
+    Canal         string `json:"canal"`
+    Local         string `json:"local,omitempty"`
+    FilialID      *uint  `json:"filialId,omitempty"`
+    Filial        string `json:"filial,omitempty"`
 }
\ No newline at end of file
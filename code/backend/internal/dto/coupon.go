@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type CouponDTO struct {
     Code        string `json:"codigo"`
     RewardID    string `json:"vantagemId"`
@@ -7,4 +9,43 @@ type CouponDTO struct {
     Used        bool   `json:"usado"`
     RedeemedAt  string `json:"dataResgate"`
     ExpiresAt   string `json:"dataValidade"`
-}
\ No newline at end of file
+}
+
+// CompanyKeyDTO é a chave pública publicada para a conferência offline dos cupons.
+type CompanyKeyDTO struct {
+    KeyID     string     `json:"kid"`
+    Algorithm string     `json:"alg"`
+    PublicKey string     `json:"publicKey"`
+    CreatedAt time.Time  `json:"createdAt"`
+    RetiredAt *time.Time `json:"retiredAt,omitempty"`
+}
+
+type CouponSyncItemDTO struct {
+    Token  string    `json:"token" binding:"required"`
+    UsedAt time.Time `json:"usadoEm" binding:"required"`
+}
+
+type CouponSyncDTO struct {
+    Coupons  []CouponSyncItemDTO `json:"cupons" binding:"required,min=1,max=500,dive"`
+    Location string              `json:"local" binding:"max=120"`
+    // Filial do PDV; obrigatória para cupons de vantagens restritas a filiais
+    BranchID *uint               `json:"filialId"`
+}
+
+// CouponValidateDTO identifica o cupom apresentado no caixa por token assinado, hash ou
+// código, nessa ordem de preferência.
+type CouponValidateDTO struct {
+    Code     string `json:"codigo"`
+    Hash     string `json:"hash"`
+    Token    string `json:"token"`
+    Location string `json:"local" binding:"max=120"`
+    BranchID *uint  `json:"filialId"`
+}
+
+// CouponSyncResultDTO traz o resultado de cada cupom sincronizado; Code segue os códigos
+// de erro da API quando o cupom não pôde ser baixado.
+type CouponSyncResultDTO struct {
+    CouponID uint   `json:"couponId,omitempty"`
+    Status   string `json:"status"`
+    Code     string `json:"code,omitempty"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "campuscash-backend/internal/model"
+
+type InstitutionSettingsDTO struct {
+	ID                        uint   `json:"id"`
+	Name                      string `json:"name"`
+	CoinName                  string `json:"coinName"`
+	CoinExpiryDays            uint   `json:"coinExpiryDays"`
+	AllowUnenrolledRecipients bool   `json:"allowUnenrolledRecipients"`
+	// Verdadeiro quando a instituição não tem calendário próprio e segue o global
+	SharedCalendar    bool                    `json:"sharedCalendar"`
+	Semesters         []model.Semester        `json:"semesters"`
+	AllotmentPolicies []model.AllotmentPolicy `json:"allotmentPolicies"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+type ProfessorInviteDTO struct {
+	Name          string `json:"name" binding:"required"`
+	Email         string `json:"email" binding:"required,email"`
+	CPF           string `json:"cpf" binding:"required"`
+	Department    string `json:"department" binding:"required"`
+	InstitutionID uint   `json:"institutionId" binding:"required"`
+}
+
+type ProfessorImportRowDTO struct {
+	Line   int    `json:"line"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	UserID *uint  `json:"userId,omitempty"`
+	// Code e Details seguem o envelope de erro da API; Error é a mensagem já traduzida
+	Code    string                 `json:"code,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Error   string                 `json:"error,omitempty"`
+}
+
+type ProfessorImportReportDTO struct {
+	Total   int                     `json:"total"`
+	Created int                     `json:"created"`
+	Failed  int                     `json:"failed"`
+	Rows    []ProfessorImportRowDTO `json:"rows"`
+}
+
+type InvitationInfoDTO struct {
+	Name        string  `json:"name"`
+	Email       string  `json:"email"`
+	Institution *string `json:"institution,omitempty"`
+}
+
+type AccountActivationDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
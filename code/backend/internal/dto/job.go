@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type JobDTO struct {
+	Name      string     `json:"name"`
+	Schedule  string     `json:"schedule"`
+	NextRunAt time.Time  `json:"nextRunAt"`
+	Running   bool       `json:"running"`
+	LastRun   *JobRunDTO `json:"lastRun,omitempty"`
+}
+
+type JobRunDTO struct {
+	ID         uint            `json:"id"`
+	JobName    string          `json:"job"`
+	Trigger    string          `json:"trigger"`
+	Status     string          `json:"status"`
+	Instance   string          `json:"instance"`
+	Error      string          `json:"error,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
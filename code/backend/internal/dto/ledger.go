@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type LedgerDiscrepancyDTO struct {
+	AccountID     uint  `json:"accountId"`
+	UserID        *uint `json:"userId,omitempty"`
+	Computed      int64 `json:"saldoCalculado"`
+	CachedAccount int64 `json:"saldoConta"`
+	CachedUser    *uint `json:"saldoUsuario,omitempty"`
+}
+
+type LedgerPostingDTO struct {
+	EntryID       uint      `json:"lancamentoId"`
+	Amount        int64     `json:"valor"`
+	Type          string    `json:"tipo"`
+	TransactionID *uint     `json:"transacaoId,omitempty"`
+	Memo          string    `json:"descricao"`
+	CreatedAt     time.Time `json:"data"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+type MFAStatusDTO struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"`
+	RecoveryCodesLeft int64 `json:"recoveryCodesLeft"`
+}
+
+// MFAEnrollmentDTO traz o segredo e a URI otpauth:// que o frontend mostra como QR code.
+type MFAEnrollmentDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type MFACodeDTO struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFADisableDTO struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAChallengeDTO é a resposta do login quando falta o segundo fator.
+type MFAChallengeDTO struct {
+	MFARequired    bool   `json:"mfaRequired"`
+	SetupRequired  bool   `json:"setupRequired"`
+	ChallengeToken string `json:"challengeToken"`
+	ExpiresIn      int    `json:"expiresIn"`
+}
+
+type MFAChallengeTokenDTO struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+}
+
+type MFALoginDTO struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
@@ -27,7 +27,7 @@ type ProfessorTransferDTO struct {
 
 type ProfessorUpdateDTO struct {
     Name        string `json:"name"`
-    Email       string `json:"email" binding:"email"`
+    Email       string `json:"email"`
     CPF         string `json:"cpf"`
     Department  string `json:"department"`
     Institution string `json:"institutionId"`
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"campuscash-backend/internal/model"
+	"time"
+)
+
+type ReconciliationReportDTO struct {
+	GeneratedAt   time.Time                      `json:"generatedAt"`
+	UsersChecked  int                            `json:"usersChecked"`
+	RepairApplied bool                           `json:"repairApplied"`
+	Discrepancies []ReconciliationDiscrepancyDTO `json:"discrepancies"`
+}
+
+type ReconciliationDiscrepancyDTO struct {
+	UserID     uint           `json:"userId"`
+	Name       string         `json:"name"`
+	Role       model.UserRole `json:"role"`
+	Balance    uint           `json:"balance"`
+	Expected   int64          `json:"expected"`
+	Difference int64          `json:"difference"`
+	GivesIn    uint           `json:"givesIn"`
+	GivesOut   uint           `json:"givesOut"`
+	RedeemsIn  uint           `json:"redeemsIn"`
+	RedeemsOut uint           `json:"redeemsOut"`
+	// Transações que nunca movimentaram o saldo (sem lançamento no livro-razão)
+	UnpostedTransactions []model.Transaction `json:"unpostedTransactions"`
+	// Lançamentos que movimentaram o saldo sem transação correspondente
+	UnrecordedEntries []LedgerPostingDTO `json:"unrecordedEntries"`
+	Repaired          bool               `json:"repaired"`
+	RepairError       string             `json:"repairError,omitempty"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type RewardCreateDTO struct {
     Title       string `json:"titulo" binding:"required"`
     Description string `json:"descricao" binding:"required"`
@@ -7,4 +9,25 @@ type RewardCreateDTO struct {
     ImageURL    string `json:"imagem"` // Opcional, imagem pode ser enviada separadamente
     CompanyID   uint   `json:"empresaId"` // Preenchido automaticamente pelo controller
     Category    string `json:"categoria" binding:"required"`
+    // Instituições que enxergam a vantagem; vazio abre para todas. Na edição,
+    // omitir o campo mantém a restrição atual
+    InstitutionIDs []uint `json:"instituicoes"`
+    // Filiais onde o cupom pode ser usado; vazio vale em todas. Omitir na edição
+    // mantém a restrição atual
+    BranchIDs []uint `json:"filiais"`
+    // Estoque inicial (nil é ilimitado) e limites por aluno; na edição o estoque é
+    // ignorado e só muda pela rota de reposição
+    Stock         *uint `json:"estoque"`
+    MaxPerStudent uint  `json:"limitePorAluno"`
+    PeriodLimit   uint  `json:"limitePorPeriodo"`
+    PeriodDays    uint  `json:"periodoDias"`
+    // Janela da promoção e validade dos cupons emitidos
+    PublishAt       *time.Time `json:"publicarEm"`
+    ExpireAt        *time.Time `json:"expiraEm"`
+    CouponValidDays uint       `json:"validadeCupomDias"`
+    RefundOnExpiry  bool       `json:"reembolsarVencidos"`
+}
+
+type RewardStockDTO struct {
+    Stock *uint `json:"estoque"`
 }
\ No newline at end of file
@@ -0,0 +1,24 @@
+package dto
+
+type TokenPairDTO struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+type RefreshTokenDTO struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type EmailDTO struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type TokenDTO struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type PasswordResetDTO struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
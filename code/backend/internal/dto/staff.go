@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type StaffDTO struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type StaffCreateDTO struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+type StaffRoleUpdateDTO struct {
+	Role string `json:"role" binding:"required"`
+}
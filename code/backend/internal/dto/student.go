@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type StudentRegisterDTO struct {
     Name        string `json:"name" binding:"required"`
     Email       string `json:"email" binding:"required,email"`
@@ -24,12 +26,13 @@ type StudentProfileDTO struct {
 }
 
 type StudentBalanceDTO struct {
-    Balance uint `json:"saldoMoedas"`
+    Balance     uint                `json:"saldoMoedas"`
+    Expirations []CoinExpirationDTO `json:"expiracoes"`
 }
 
 type StudentUpdateDTO struct {
     Name        string `json:"name"`
-    Email       string `json:"email" binding:"email"`
+    Email       string `json:"email"`
     CPF         string `json:"cpf"`
     RG          string `json:"rg"`
     Address     string `json:"address"`
@@ -49,4 +52,8 @@ type StudentSearchDTO struct {
     ID    uint   `json:"id"`
     Name  string `json:"name"`
     Email string `json:"email"`
-}
\ No newline at end of file
+}
+type CoinExpirationDTO struct {
+    Amount    uint      `json:"quantidade"`
+    ExpiresAt time.Time `json:"dataExpiracao"`
+}
@@ -0,0 +1,132 @@
+package dto
+
+import (
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/validator"
+	"strings"
+)
+
+// Nas atualizações de perfil os campos são opcionais: só o que foi enviado é validado.
+
+const maxNameLength = 120
+
+func (in *StudentUpdateDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	in.Name = strings.TrimSpace(in.Name)
+	errs.MaxLength("name", in.Name, maxNameLength)
+	if in.Email != "" {
+		errs.Email("email", &in.Email)
+	}
+	if in.CPF != "" {
+		errs.CPF("cpf", &in.CPF)
+	}
+	in.RG = strings.TrimSpace(in.RG)
+	in.Address = strings.TrimSpace(in.Address)
+	in.Institution = strings.TrimSpace(in.Institution)
+	in.Course = strings.TrimSpace(in.Course)
+	return errs
+}
+
+func (in *ProfessorUpdateDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	in.Name = strings.TrimSpace(in.Name)
+	errs.MaxLength("name", in.Name, maxNameLength)
+	if in.Email != "" {
+		errs.Email("email", &in.Email)
+	}
+	if in.CPF != "" {
+		errs.CPF("cpf", &in.CPF)
+	}
+	in.Department = strings.TrimSpace(in.Department)
+	in.Institution = strings.TrimSpace(in.Institution)
+	return errs
+}
+
+func (in *CompanyUpdateDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	in.Name = strings.TrimSpace(in.Name)
+	errs.MaxLength("nomeFantasia", in.Name, maxNameLength)
+	if in.Email != "" {
+		errs.Email("email", &in.Email)
+	}
+	if in.CNPJ != "" {
+		errs.CNPJ("cnpj", &in.CNPJ)
+	}
+	in.Address = strings.TrimSpace(in.Address)
+	return errs
+}
+
+// Limite por período sem a duração do período não tem como ser aplicado, e a promoção
+// precisa terminar depois de começar.
+func (in *RewardCreateDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	if in.PeriodLimit > 0 && in.PeriodDays == 0 {
+		errs.Add("periodoDias", validator.CodeRequired, "")
+	}
+	if in.PublishAt != nil && in.ExpireAt != nil && !in.ExpireAt.After(*in.PublishAt) {
+		errs.Add("expiraEm", validator.CodeInvalid, "")
+	}
+	return errs
+}
+
+func (in *StaffCreateDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	if errs.Required("name", &in.Name) {
+		errs.MaxLength("name", in.Name, maxNameLength)
+	}
+	errs.Email("email", &in.Email)
+	errs.Password("password", in.Password)
+	if errs.Required("role", &in.Role) && !validStaffRole(in.Role) {
+		errs.Add("role", validator.CodeInvalid, "")
+	}
+	return errs
+}
+
+func (in *StaffRoleUpdateDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	if !validStaffRole(in.Role) {
+		errs.Add("role", validator.CodeInvalid, "")
+	}
+	return errs
+}
+
+func validStaffRole(role string) bool {
+	return model.StaffRole(role).Permissions() != nil
+}
+
+const maxOpeningHoursLength = 200
+
+// As coordenadas são obrigatórias: sem elas a filial não entra na busca por proximidade.
+func (in *BranchSaveDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	if errs.Required("nome", &in.Name) {
+		errs.MaxLength("nome", in.Name, maxNameLength)
+	}
+	in.Address = strings.TrimSpace(in.Address)
+	in.OpeningHours = strings.TrimSpace(in.OpeningHours)
+	errs.MaxLength("horarioFuncionamento", in.OpeningHours, maxOpeningHoursLength)
+	validCoordinates(&errs, "latitude", "longitude", in.Latitude, in.Longitude)
+	return errs
+}
+
+func (in *NearbyRewardsDTO) Validate() validator.Errors {
+	var errs validator.Errors
+	validCoordinates(&errs, "lat", "lng", in.Lat, in.Lng)
+	if !(in.Radius > 0 && in.Radius <= MaxNearbyRadiusKm) {
+		errs.Add("radius", validator.CodeInvalid, "")
+	}
+	return errs
+}
+
+func validCoordinates(errs *validator.Errors, latField, lngField string, lat, lng *float64) {
+	if lat == nil {
+		errs.Add(latField, validator.CodeRequired, "")
+	} else if !(*lat >= -90 && *lat <= 90) {
+		errs.Add(latField, validator.CodeInvalid, "")
+	}
+	if lng == nil {
+		errs.Add(lngField, validator.CodeRequired, "")
+	} else if !(*lng >= -180 && *lng <= 180) {
+		errs.Add(lngField, validator.CodeInvalid, "")
+	}
+}
@@ -0,0 +1,53 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+type Lang string
+
+const (
+	PtBR Lang = "pt-BR"
+	En   Lang = "en"
+
+	Default = PtBR
+)
+
+var matcher = language.NewMatcher([]language.Tag{language.BrazilianPortuguese, language.English})
+
+// FromAcceptLanguage escolhe o idioma da resposta; cabeçalho ausente, inválido ou sem
+// idioma suportado cai no português.
+func FromAcceptLanguage(header string) Lang {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	tag, _, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	if base, _ := tag.Base(); base.String() == "en" {
+		return En
+	}
+	return PtBR
+}
+
+// Message traduz a chave e substitui os marcadores "{nome}" pelos parâmetros.
+// Chaves sem tradução são devolvidas como estão.
+func Message(lang Lang, key string, params map[string]interface{}) string {
+	texts, ok := catalog[key]
+	if !ok {
+		return key
+	}
+	msg, ok := texts[lang]
+	if !ok {
+		msg = texts[Default]
+	}
+	for k, v := range params {
+		msg = strings.ReplaceAll(msg, "{"+k+"}", fmt.Sprint(v))
+	}
+	return msg
+}
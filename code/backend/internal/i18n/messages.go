@@ -0,0 +1,132 @@
+package i18n
+
+// catalog traz as mensagens por código de erro (ver apperror). Toda chave precisa de
+// texto em português; o inglês cai no português quando falta.
+var catalog = map[string]map[Lang]string{
+	// Genéricos
+	"internal_error":    {PtBR: "erro interno; tente novamente mais tarde", En: "internal error; please try again later"},
+	"invalid_request":   {PtBR: "corpo da requisição inválido", En: "invalid request body"},
+	"invalid_id":        {PtBR: "identificador inválido", En: "invalid identifier"},
+	"validation_failed": {PtBR: "dados inválidos", En: "invalid data"},
+	"conflict":          {PtBR: "dados já cadastrados", En: "data already registered"},
+	"unauthorized":      {PtBR: "não autorizado", En: "unauthorized"},
+	"not_found":         {PtBR: "não encontrado", En: "not found"},
+
+	// Erros de campo (FieldError.Code)
+	"field.required":  {PtBR: "campo obrigatório", En: "required field"},
+	"field.invalid":   {PtBR: "valor inválido", En: "invalid value"},
+	"field.too_short": {PtBR: "valor muito curto", En: "value too short"},
+	"field.too_long":  {PtBR: "valor muito longo", En: "value too long"},
+	"field.weak":      {PtBR: "senha fraca: use letras maiúsculas, minúsculas e números", En: "weak password: use upper and lower case letters and digits"},
+	"field.duplicate": {PtBR: "já cadastrado", En: "already registered"},
+
+	// Autenticação e sessão
+	"missing_auth_header":      {PtBR: "cabeçalho Authorization ausente", En: "missing Authorization header"},
+	"invalid_token":            {PtBR: "token inválido", En: "invalid token"},
+	"token_revoked":            {PtBR: "sessão encerrada; entre novamente", En: "session ended; please sign in again"},
+	"role_not_allowed":         {PtBR: "perfil sem permissão para este recurso", En: "your role cannot access this resource"},
+	"permission_denied":        {PtBR: "seu cargo não permite esta operação", En: "your position does not allow this operation"},
+	"idempotency_key_too_long": {PtBR: "Idempotency-Key muito longo", En: "Idempotency-Key is too long"},
+	"idempotency_key_reused":   {PtBR: "Idempotency-Key já utilizada com outra requisição", En: "Idempotency-Key already used with a different request"},
+	"idempotency_in_progress":  {PtBR: "requisição com esta Idempotency-Key já está em andamento", En: "a request with this Idempotency-Key is already in progress"},
+
+	// Contas e login
+	"invalid_credentials":   {PtBR: "credenciais inválidas", En: "invalid credentials"},
+	"account_pending":       {PtBR: "conta pendente de ativação", En: "account pending activation"},
+	"account_disabled":      {PtBR: "conta desativada", En: "account disabled"},
+	"email_not_verified":    {PtBR: "email não verificado", En: "email not verified"},
+	"login_throttled":       {PtBR: "aguarde {retryAfter} segundos antes de tentar novamente", En: "wait {retryAfter} seconds before trying again"},
+	"login_locked":          {PtBR: "muitas tentativas sem sucesso; acesso bloqueado por {retryAfter} segundos", En: "too many failed attempts; access blocked for {retryAfter} seconds"},
+	"invalid_refresh_token": {PtBR: "refresh token inválido ou expirado", En: "invalid or expired refresh token"},
+	"refresh_token_reused":  {PtBR: "refresh token reutilizado; sessão encerrada", En: "refresh token reused; session ended"},
+	"session_revoked":       {PtBR: "sessão encerrada", En: "session ended"},
+	"invalid_account_token": {PtBR: "link inválido ou já utilizado", En: "invalid or already used link"},
+	"account_token_expired": {PtBR: "link expirado", En: "link expired"},
+	"invalid_password":      {PtBR: "senha incorreta", En: "incorrect password"},
+
+	// Autenticação em dois fatores
+	"mfa_not_enrolled":      {PtBR: "autenticação em dois fatores não iniciada", En: "two-factor authentication setup not started"},
+	"mfa_already_enabled":   {PtBR: "autenticação em dois fatores já está ativa", En: "two-factor authentication is already enabled"},
+	"mfa_not_enabled":       {PtBR: "autenticação em dois fatores não está ativa", En: "two-factor authentication is not enabled"},
+	"mfa_required":          {PtBR: "autenticação em dois fatores é obrigatória para este perfil", En: "two-factor authentication is required for this role"},
+	"invalid_mfa_code":      {PtBR: "código de verificação inválido", En: "invalid verification code"},
+	"invalid_mfa_challenge": {PtBR: "desafio de login inválido ou expirado; entre novamente", En: "invalid or expired login challenge; please sign in again"},
+
+	// Usuários e administração
+	"user_not_found":               {PtBR: "usuário não encontrado", En: "user not found"},
+	"branch_not_found":             {PtBR: "filial não encontrada", En: "branch not found"},
+	"branch_in_use":                {PtBR: "a filial está vinculada a vantagens; remova-a delas antes de excluir", En: "the branch is linked to rewards; remove it from them before deleting"},
+	"branch_required":              {PtBR: "informe a filial: esta vantagem só vale em filiais específicas", En: "branch required: this reward is only valid at specific branches"},
+	"reward_not_at_branch":         {PtBR: "a vantagem não vale nesta filial", En: "the reward is not valid at this branch"},
+	"staff_not_found":              {PtBR: "funcionário não encontrado", En: "staff member not found"},
+	"student_not_found":            {PtBR: "aluno não encontrado", En: "student not found"},
+	"professor_not_found":          {PtBR: "professor não encontrado", En: "professor not found"},
+	"company_not_found":            {PtBR: "empresa não encontrada", En: "company not found"},
+	"invalid_role":                 {PtBR: "papel inválido", En: "invalid role"},
+	"email_in_use":                 {PtBR: "email já cadastrado", En: "email already registered"},
+	"cpf_in_use":                   {PtBR: "CPF já cadastrado", En: "CPF already registered"},
+	"self_modification":            {PtBR: "administradores não podem alterar a própria conta por aqui", En: "administrators cannot change their own account here"},
+	"role_change_with_balance":     {PtBR: "zere o saldo do usuário antes de alterar o papel", En: "clear the user's balance before changing the role"},
+	"institution_not_found":        {PtBR: "instituição não encontrada", En: "institution not found"},
+	"institution_exists":           {PtBR: "instituição já cadastrada", En: "institution already registered"},
+	"institution_in_use":           {PtBR: "instituição possui usuários, semestres ou disciplinas vinculados", En: "institution has linked users, semesters or courses"},
+	"institution_change_forbidden": {PtBR: "a instituição só pode ser alterada por um administrador", En: "the institution can only be changed by an administrator"},
+	"invalid_semester_period":      {PtBR: "o fim do semestre deve ser posterior ao início", En: "the semester must end after it starts"},
+	"semester_overlap":             {PtBR: "o período se sobrepõe a outro semestre", En: "the period overlaps another semester"},
+
+	// Disciplinas, turmas e matrículas
+	"course_not_found":             {PtBR: "disciplina não encontrada", En: "course not found"},
+	"course_exists":                {PtBR: "já existe uma disciplina com esse código na instituição", En: "the institution already has a course with this code"},
+	"course_in_use":                {PtBR: "a disciplina possui turmas", En: "the course has classes"},
+	"class_not_found":              {PtBR: "turma não encontrada", En: "class not found"},
+	"enrollment_not_found":         {PtBR: "o aluno não está matriculado nesta turma", En: "the student is not enrolled in this class"},
+	"professor_not_in_institution": {PtBR: "o professor não pertence à instituição da disciplina", En: "the professor does not belong to the course's institution"},
+	"student_not_in_institution":   {PtBR: "o aluno não pertence à instituição da turma", En: "the student does not belong to the class's institution"},
+	"student_not_enrolled":         {PtBR: "o aluno não está matriculado em nenhuma das suas turmas", En: "the student is not enrolled in any of your classes"},
+
+	// Convites e importação de professores
+	"invalid_invitation":         {PtBR: "convite inválido ou já utilizado", En: "invalid or already used invitation"},
+	"invitation_expired":         {PtBR: "convite expirado", En: "invitation expired"},
+	"invalid_professor":          {PtBR: "dados do professor inválidos (campo {field})", En: "invalid professor data (field {field})"},
+	"invalid_csv":                {PtBR: "arquivo CSV inválido", En: "invalid CSV file"},
+	"invalid_csv.missing_header": {PtBR: "arquivo CSV inválido: cabeçalho ausente", En: "invalid CSV file: missing header"},
+	"invalid_csv.missing_column": {PtBR: "arquivo CSV inválido: coluna {column} ausente", En: "invalid CSV file: missing column {column}"},
+	"malformed_row":              {PtBR: "linha malformada", En: "malformed line"},
+	"duplicate_row":              {PtBR: "{field} repetido no arquivo (linha {line})", En: "{field} repeated in the file (line {line})"},
+	"file_too_large":             {PtBR: "arquivo muito grande (máximo de {maxBytes} bytes)", En: "file too large (maximum {maxBytes} bytes)"},
+
+	// Moedas, vantagens e cupons
+	"insufficient_balance":             {PtBR: "saldo insuficiente", En: "insufficient balance"},
+	"insufficient_balance.coins_spent": {PtBR: "o aluno já utilizou as moedas recebidas", En: "the student has already spent the received coins"},
+	"invalid_amount":                   {PtBR: "o valor deve ser maior que zero", En: "the amount must be greater than zero"},
+	"transaction_not_found":            {PtBR: "transação não encontrada", En: "transaction not found"},
+	"not_transaction_owner":            {PtBR: "transação não pertence ao usuário", En: "the transaction does not belong to the user"},
+	"not_reversible":                   {PtBR: "transação não pode ser estornada", En: "the transaction cannot be reversed"},
+	"reversal_window_expired":          {PtBR: "prazo para estorno expirado", En: "the reversal period has expired"},
+	"already_reversed":                 {PtBR: "transação já foi estornada", En: "the transaction has already been reversed"},
+	"reward_not_found":                 {PtBR: "vantagem não encontrada", En: "reward not found"},
+	"not_reward_owner":                 {PtBR: "a vantagem pertence a outra empresa", En: "the reward belongs to another company"},
+	"reward_suspended":                 {PtBR: "vantagem suspensa pela moderação", En: "reward suspended by moderation"},
+	"reward_sold_out":                  {PtBR: "vantagem esgotada", En: "reward sold out"},
+	"reward_in_stock":                  {PtBR: "a vantagem ainda tem estoque", En: "the reward is still in stock"},
+	"redemption_limit_reached":         {PtBR: "limite de {limit} resgates desta vantagem atingido", En: "redemption limit of {limit} reached for this reward"},
+	"redemption_limit_reached.period":  {PtBR: "limite de {limit} resgates a cada {periodDays} dias atingido", En: "limit of {limit} redemptions every {periodDays} days reached"},
+	"coupon_not_found":                 {PtBR: "cupom não encontrado", En: "coupon not found"},
+	"coupon_already_used":              {PtBR: "cupom já foi utilizado", En: "coupon already used"},
+	"coupon_refunded":                  {PtBR: "cupom foi reembolsado", En: "coupon was refunded"},
+	"invalid_coupon_token":             {PtBR: "token de cupom inválido", En: "invalid coupon token"},
+	"coupon_cancelled":                 {PtBR: "cupom foi cancelado", En: "coupon was cancelled"},
+	"coupon_expired":                   {PtBR: "cupom fora da validade", En: "coupon has expired"},
+	"reward_not_available":             {PtBR: "vantagem indisponível no momento", En: "reward is not available right now"},
+	"reward_not_available.upcoming":    {PtBR: "a vantagem ainda não foi lançada", En: "the reward has not been launched yet"},
+	"reward_not_available.expired":     {PtBR: "a promoção desta vantagem terminou", En: "this reward's promotion has ended"},
+
+	// Imagens
+	"invalid_image":      {PtBR: "não foi possível processar a imagem", En: "could not process the image"},
+	"invalid_image_type": {PtBR: "tipo de imagem inválido", En: "invalid image type"},
+	"image_not_found":    {PtBR: "imagem não encontrada", En: "image not found"},
+
+	// Jobs
+	"job_not_found": {PtBR: "job não encontrado", En: "job not found"},
+	"job_running":   {PtBR: "job já está em execução", En: "job is already running"},
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
 	"net/http"
 	"strings"
 
@@ -9,40 +11,141 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenValidator confere no servidor se o token ainda vale (sessão não revogada e
+// versão de token do usuário atual). Definido na inicialização das rotas.
+type TokenValidator func(userID uint, sessionID string, version uint) error
+
+var tokenValidator TokenValidator
+
+var (
+	ErrMissingAuthHeader = apperror.New("missing_auth_header", http.StatusUnauthorized)
+	ErrInvalidToken      = apperror.New("invalid_token", http.StatusUnauthorized)
+	ErrTokenRevoked      = apperror.New("token_revoked", http.StatusUnauthorized)
+	ErrRoleNotAllowed    = apperror.New("role_not_allowed", http.StatusForbidden)
+	ErrPermissionDenied  = apperror.New("permission_denied", http.StatusForbidden)
+)
+
+func SetTokenValidator(v TokenValidator) {
+	tokenValidator = v
+}
+
 func Auth(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		header := c.GetHeader("Authorization")
-		if header == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing auth header"})
+		if c.GetHeader("Authorization") == "" {
+			abortWithError(c, ErrMissingAuthHeader)
 			return
 		}
-		tokenStr := strings.TrimPrefix(header, "Bearer ")
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			return config.JWTSecret, nil
-		})
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		if err := authenticate(c, roles); err != nil {
+			abortWithError(c, err)
 			return
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid claims"})
-			return
+		c.Next()
+	}
+}
+
+// OptionalAuth identifica o usuário quando há token, mas também aceita visitantes
+// anônimos; usado nas rotas públicas cujo conteúdo depende de quem pede.
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			if err := authenticate(c, nil); err != nil {
+				abortWithError(c, err)
+				return
+			}
 		}
-		userRole := claims["role"].(string)
-		roleOk := len(roles) == 0
-		for _, r := range roles {
-			if userRole == r {
-				roleOk = true
-				break
+		c.Next()
+	}
+}
+
+// authenticate valida o token do cabeçalho Authorization e preenche userID, sessionID e
+// role; para empresas e funcionários, também companyID e permissions.
+func authenticate(c *gin.Context, roles []string) error {
+	tokenStr := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		return config.JWTSecret, nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil || !token.Valid {
+		return ErrInvalidToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ErrInvalidToken
+	}
+	// Token com assinatura válida mas sem os claims obrigatórios também é inválido
+	userRole, ok := claims["role"].(string)
+	if !ok || userRole == "" {
+		return ErrInvalidToken
+	}
+	rawID, ok := claims["id"].(float64)
+	if !ok || rawID <= 0 {
+		return ErrInvalidToken
+	}
+	roleOk := len(roles) == 0
+	for _, r := range roles {
+		if userRole == r {
+			roleOk = true
+			break
+		}
+	}
+	if !roleOk {
+		return ErrRoleNotAllowed
+	}
+	userID := uint(rawID)
+	// Tokens sem sessão são anteriores aos refresh tokens e não podem ser revogados
+	sessionID, _ := claims["sid"].(string)
+	version, _ := claims["ver"].(float64)
+	if sessionID == "" {
+		return ErrInvalidToken
+	}
+	if tokenValidator != nil {
+		if err := tokenValidator(userID, sessionID, uint(version)); err != nil {
+			return ErrTokenRevoked
+		}
+	}
+	c.Set("userID", userID)
+	c.Set("sessionID", sessionID)
+	c.Set("role", userRole)
+	if companyID, ok := claims["cid"].(float64); ok {
+		var perms []string
+		raw, _ := claims["perms"].([]interface{})
+		for _, p := range raw {
+			if perm, ok := p.(string); ok {
+				perms = append(perms, perm)
+			}
+		}
+		c.Set("companyID", uint(companyID))
+		c.Set("permissions", perms)
+	} else if userRole == string(model.CompanyRole) {
+		// Tokens de empresa emitidos antes dos funcionários não trazem o escopo
+		companyID, perms := model.CompanyScope(&model.User{ID: userID, Role: model.CompanyRole})
+		c.Set("companyID", companyID)
+		c.Set("permissions", perms)
+	}
+	return nil
+}
+
+// RequirePermission exige uma das permissões do token; vem depois de Auth.
+func RequirePermission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, granted := range c.GetStringSlice("permissions") {
+			for _, perm := range perms {
+				if granted == perm {
+					c.Next()
+					return
+				}
 			}
 		}
-		if !roleOk {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "unauthorized role"})
+		abortWithError(c, ErrPermissionDenied)
+	}
+}
+
+// InternalSecret protege endpoints internos (jobs e relatórios) com o X-Cron-Secret.
+func InternalSecret() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-Cron-Secret") != config.CronSecret {
+			abortWithError(c, apperror.ErrUnauthorized)
 			return
 		}
-		c.Set("userID", uint(claims["id"].(float64)))
-		c.Set("role", userRole)
 		c.Next()
 	}
 }
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"campuscash-backend/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func authRequest(t *testing.T, claims jwt.MapClaims) *httptest.ResponseRecorder {
+	t.Helper()
+	config.JWTSecret = []byte("test-secret")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(config.JWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.Recovery(), ErrorHandler())
+	r.GET("/me", Auth(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.GetUint("userID")})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthAcceptsCompleteToken(t *testing.T) {
+	w := authRequest(t, jwt.MapClaims{"id": 7, "role": "student", "sid": "s1", "exp": time.Now().Add(time.Minute).Unix()})
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava 200, obteve %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthRejectsTokenWithoutRequiredClaims(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Unix()
+	cases := map[string]jwt.MapClaims{
+		"sem role":        {"id": 7, "sid": "s1", "exp": exp},
+		"sem id":          {"role": "student", "sid": "s1", "exp": exp},
+		"role não string": {"id": 7, "role": 1, "sid": "s1", "exp": exp},
+	}
+	for name, claims := range cases {
+		w := authRequest(t, claims)
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), `"code":"invalid_token"`) {
+			t.Fatalf("%s: esperava 401 invalid_token, obteve %d %s", name, w.Code, w.Body.String())
+		}
+	}
+}
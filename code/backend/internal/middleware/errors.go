@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/i18n"
+	"campuscash-backend/pkg/validator"
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler converte o último erro registrado com c.Error na resposta padrão:
+//
+//	{"code": "reward_not_found", "message": "...", "error": "...", "details": {...}}
+//
+// "error" repete a mensagem para os clientes que ainda leem esse campo. Erros que não
+// são *apperror.Error viram internal_error, sem expor o texto original.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		renderError(c)
+	}
+}
+
+func renderError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	err := c.Errors.Last().Err
+	lang := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+
+	appErr := toAppError(c, err, lang)
+	message := i18n.Message(lang, appErr.Key, appErr.Details)
+	body := gin.H{"code": appErr.Code, "message": message, "error": message}
+	if len(appErr.Details) > 0 {
+		body["details"] = appErr.Details
+	}
+	c.Header("Content-Language", string(lang))
+	c.AbortWithStatusJSON(appErr.Status, body)
+}
+
+func toAppError(c *gin.Context, err error, lang i18n.Lang) *apperror.Error {
+	var fields validator.Errors
+	if errors.As(err, &fields) {
+		localized := make(validator.Errors, len(fields))
+		for i, fe := range fields {
+			fe.Message = i18n.Message(lang, "field."+fe.Code, nil)
+			localized[i] = fe
+		}
+		if fields.Conflict() {
+			return apperror.ErrConflict.With("fields", localized)
+		}
+		return apperror.ErrValidation.With("fields", localized)
+	}
+
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) {
+		log.Printf("Unhandled error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		return apperror.ErrInternal
+	}
+	if appErr.Status >= 500 {
+		log.Printf("Error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+	return appErr
+}
+
+// abortWithError interrompe a cadeia; a resposta é montada pelo ErrorHandler.
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"bytes"
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const IdempotencyHeader = "Idempotency-Key"
+
+var (
+	ErrIdempotencyKeyTooLong = apperror.New("idempotency_key_too_long", http.StatusBadRequest)
+	ErrIdempotencyKeyReused  = apperror.New("idempotency_key_reused", http.StatusConflict)
+	ErrIdempotencyInProgress = apperror.New("idempotency_in_progress", http.StatusConflict)
+)
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency faz com que reenvios com o mesmo Idempotency-Key devolvam a resposta
+// original. Reutilizar a chave com outro corpo, em outro caminho (inclusive outro :id
+// da mesma rota) ou enquanto a primeira requisição ainda está em andamento retorna
+// 409. Deve ser registrado depois de Auth.
+func Idempotency(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			abortWithError(c, ErrIdempotencyKeyTooLong)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, apperror.ErrInvalidBody.Wrap(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		userID := c.GetUint("userID")
+		now := time.Now()
+
+		var existing model.IdempotencyKey
+		err = db.Where("user_id = ? AND key = ? AND expires_at > ?", userID, key, now).First(&existing).Error
+		if err == nil {
+			replayIdempotent(c, &existing, fingerprint)
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			abortWithError(c, err)
+			return
+		}
+
+		db.Where("expires_at <= ?", now).Delete(&model.IdempotencyKey{})
+
+		record := model.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(time.Duration(config.IdempotencyTTLHours) * time.Hour),
+		}
+		if err := db.Create(&record).Error; err != nil {
+			// Outra requisição com a mesma chave reservou o registro primeiro
+			abortWithError(c, ErrIdempotencyInProgress)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		// Erros registrados pelo handler são escritos aqui para entrarem na resposta guardada
+		renderError(c)
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			// Falhas do servidor liberam a chave para que o cliente possa tentar de novo
+			db.Delete(&record)
+			return
+		}
+		db.Model(&record).Updates(map[string]interface{}{
+			"status_code":   status,
+			"response_body": recorder.body.Bytes(),
+		})
+	}
+}
+
+func replayIdempotent(c *gin.Context, record *model.IdempotencyKey, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		abortWithError(c, ErrIdempotencyKeyReused)
+		return
+	}
+	if record.StatusCode == 0 {
+		abortWithError(c, ErrIdempotencyInProgress)
+		return
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
+	c.Abort()
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newIdempotencyRouter(t *testing.T, calls *[]string) *gin.Engine {
+	t.Helper()
+	dsn := config.SQLiteDSN("file:" + filepath.Join(t.TempDir(), "idempotency.db"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.IdempotencyKey{}); err != nil {
+		t.Fatal(err)
+	}
+	config.IdempotencyTTLHours = 24
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	authed := func(c *gin.Context) { c.Set("userID", uint(7)) }
+	r.POST("/transactions/:id/reverse", authed, Idempotency(db), func(c *gin.Context) {
+		*calls = append(*calls, c.Param("id"))
+		c.JSON(http.StatusOK, gin.H{"reversed": c.Param("id")})
+	})
+	return r
+}
+
+func postWithKey(r *gin.Engine, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"reason":"erro"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyHeader, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysSamePath(t *testing.T) {
+	var calls []string
+	r := newIdempotencyRouter(t, &calls)
+
+	first := postWithKey(r, "/transactions/1/reverse", "k1")
+	second := postWithKey(r, "/transactions/1/reverse", "k1")
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Fatalf("esperava 200 nas duas, obteve %d e %d", first.Code, second.Code)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" || second.Body.String() != first.Body.String() {
+		t.Fatalf("esperava a resposta original repetida, obteve %q", second.Body.String())
+	}
+	if len(calls) != 1 {
+		t.Fatalf("esperava o handler executado uma vez, foram %d", len(calls))
+	}
+}
+
+func TestIdempotencyKeyReusedOnOtherID(t *testing.T) {
+	var calls []string
+	r := newIdempotencyRouter(t, &calls)
+
+	if w := postWithKey(r, "/transactions/1/reverse", "k1"); w.Code != http.StatusOK {
+		t.Fatalf("primeira chamada: esperava 200, obteve %d", w.Code)
+	}
+	w := postWithKey(r, "/transactions/2/reverse", "k1")
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "idempotency_key_reused") {
+		t.Fatalf("esperava 409 idempotency_key_reused, obteve %d %s", w.Code, w.Body.String())
+	}
+	if len(calls) != 1 {
+		t.Fatalf("a segunda chamada não deveria chegar ao handler: %v", calls)
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// AuditLog registra ações administrativas: quem fez, o quê e sobre qual registro.
+type AuditLog struct {
+	ID         uint   `gorm:"primaryKey"`
+	ActorID    uint   `gorm:"index"`
+	Action     string `gorm:"index"`
+	TargetType string `gorm:"index:idx_audit_target"`
+	TargetID   *uint  `gorm:"index:idx_audit_target"`
+	Details    string
+	CreatedAt  time.Time `gorm:"index"`
+}
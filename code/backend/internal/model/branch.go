@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Branch é uma loja (filial) da empresa parceira. As coordenadas alimentam a busca de
+// vantagens próximas.
+type Branch struct {
+	ID        uint `gorm:"primaryKey"`
+	CompanyID uint `gorm:"index"`
+	Name      string
+	Address   string
+	Latitude  float64 `gorm:"index:idx_branch_location"`
+	Longitude float64 `gorm:"index:idx_branch_location"`
+	// Horário de funcionamento em texto livre, ex.: "seg a sex 9h-18h, sáb 9h-13h"
+	OpeningHours string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Course é uma disciplina oferecida por uma instituição.
+type Course struct {
+	ID            uint   `gorm:"primaryKey"`
+	InstitutionID uint   `gorm:"uniqueIndex:idx_course_institution_code"`
+	Code          string `gorm:"uniqueIndex:idx_course_institution_code"`
+	Name          string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Class é uma turma de uma disciplina, conduzida por um professor da mesma instituição.
+// InstitutionID repete o da disciplina para simplificar as consultas de visibilidade.
+type Class struct {
+	ID            uint `gorm:"primaryKey"`
+	CourseID      uint `gorm:"index"`
+	InstitutionID uint `gorm:"index"`
+	ProfessorID   uint `gorm:"index"`
+	Name          string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type Enrollment struct {
+	ID        uint `gorm:"primaryKey"`
+	ClassID   uint `gorm:"uniqueIndex:idx_enrollment_class_student"`
+	StudentID uint `gorm:"uniqueIndex:idx_enrollment_class_student;index"`
+	CreatedAt time.Time
+}
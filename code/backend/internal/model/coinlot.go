@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// CoinLot representa um lote de moedas recebido por um aluno. Os lotes são
+// consumidos em ordem FIFO nos resgates e expiram em ExpiresAt.
+type CoinLot struct {
+	ID            uint  `gorm:"primaryKey"`
+	UserID        uint  `gorm:"index"`
+	TransactionID *uint `gorm:"index"`
+	Amount        uint
+	Remaining     uint
+	ExpiresAt     time.Time `gorm:"index"`
+	NotifiedAt    *time.Time
+	ExpiredAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// CoinLotConsumption registra quanto de cada lote uma transação consumiu,
+// permitindo devolver as moedas ao lote original em caso de reembolso.
+type CoinLotConsumption struct {
+	ID            uint `gorm:"primaryKey"`
+	LotID         uint `gorm:"index"`
+	TransactionID uint `gorm:"index"`
+	Amount        uint
+	CreatedAt     time.Time
+}
@@ -2,6 +2,16 @@ package model
 
 import "time"
 
+type CouponStatus string
+
+const (
+    CouponIssued    CouponStatus = "issued"
+    CouponUsed      CouponStatus = "used"
+    CouponExpired   CouponStatus = "expired"
+    CouponCancelled CouponStatus = "cancelled"
+    CouponRefunded  CouponStatus = "refunded"
+)
+
 type Coupon struct {
     ID          uint      `gorm:"primaryKey"`
     RewardID    uint
@@ -12,4 +22,34 @@ type Coupon struct {
     UsedAt      *time.Time
     CreatedAt   time.Time
     ExpiresAt   *time.Time
-}
\ No newline at end of file
+    RefundedAt  *time.Time
+
+    // Ciclo de vida: issued → used | expired | cancelled | refunded. Redeemed continua
+    // espelhando o estado "used" para os clientes antigos
+    Status      CouponStatus `gorm:"not null;default:'issued';index"`
+    ExpiredAt   *time.Time
+    CancelledAt *time.Time
+
+    // Token assinado pela empresa (pkg/coupontoken), conferível sem acesso ao servidor
+    Token       string `gorm:"type:text"`
+}
+
+// CouponValidation registra a baixa de um cupom: quem validou, por qual canal e onde. O
+// índice único em CouponID garante uma baixa por cupom.
+type CouponValidation struct {
+    ID          uint   `gorm:"primaryKey"`
+    CouponID    uint   `gorm:"uniqueIndex"`
+    CompanyID   uint   `gorm:"index"`
+    // Usuário que validou: a própria empresa ou um funcionário dela
+    ValidatedBy uint
+    // "online" (caixa consultando a API) ou "offline" (sincronizado pelo PDV)
+    Channel     string
+    // Filial onde o cupom foi usado, quando a empresa tem filiais cadastradas
+    BranchID    *uint  `gorm:"index"`
+    // Loja, caixa ou terminal informado pelo PDV
+    Location    string
+    IP          string
+    UserAgent   string
+    ValidatedAt time.Time
+    CreatedAt   time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// IdempotencyKey guarda a resposta original de uma requisição que movimenta
+// moedas, para que reenvios com o mesmo Idempotency-Key recebam o mesmo resultado.
+type IdempotencyKey struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"uniqueIndex:idx_idempotency_user_key"`
+	Key          string `gorm:"uniqueIndex:idx_idempotency_user_key"`
+	Method       string
+	Path         string
+	Fingerprint  string
+	StatusCode   int
+	ResponseBody []byte `gorm:"type:blob"`
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"index"`
+}
@@ -3,4 +3,11 @@ package model
 type Institution struct {
     ID    uint   `gorm:"primaryKey"`
     Name  string `gorm:"unique"`
+    // Validade (em dias) das moedas recebidas por alunos; 0 usa config.CoinExpiryDays
+    CoinExpiryDays uint
+    // Por padrão professores só enviam moedas a alunos matriculados nas próprias turmas;
+    // ativo, qualquer aluno da instituição pode ser premiado
+    AllowUnenrolledRecipients bool `gorm:"not null;default:false"`
+    // Nome exibido para a moeda da instituição; vazio usa "moedas"
+    CoinName string `gorm:"not null;default:''"`
 }
\ No newline at end of file
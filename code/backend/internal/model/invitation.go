@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Invitation é o convite de ativação de uma conta criada por um administrador.
+// Apenas o hash do token é guardado; o token em si só existe no link enviado por email.
+type Invitation struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"uniqueIndex"`
+	TokenHash  string `gorm:"uniqueIndex"`
+	InvitedBy  uint
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+type JobRunStatus string
+
+const (
+	JobRunning   JobRunStatus = "running"
+	JobSucceeded JobRunStatus = "success"
+	JobFailed    JobRunStatus = "failed"
+)
+
+type JobTrigger string
+
+const (
+	ScheduledTrigger JobTrigger = "schedule"
+	ManualTrigger    JobTrigger = "manual"
+)
+
+// ScheduledJob guarda o estado persistente de um job agendado. O lease
+// (LeaseOwner/LeaseExpiresAt) impede que duas réplicas executem o mesmo job ao mesmo tempo.
+type ScheduledJob struct {
+	Name           string `gorm:"primaryKey"`
+	Schedule       string
+	NextRunAt      time.Time
+	LeaseOwner     string
+	LeaseExpiresAt time.Time
+	UpdatedAt      time.Time
+}
+
+type JobRun struct {
+	ID         uint   `gorm:"primaryKey"`
+	JobName    string `gorm:"index"`
+	Trigger    JobTrigger
+	Status     JobRunStatus `gorm:"index"`
+	Instance   string
+	Error      string
+	Result     string
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type AccountKind string
+
+const (
+	MintAccount    AccountKind = "mint"
+	UserAccount    AccountKind = "user"
+	CompanyAccount AccountKind = "company"
+)
+
+// LedgerAccount guarda o saldo em cache (projeção) de uma conta do livro-razão.
+// O valor sempre pode ser reconstruído somando os Postings da conta.
+type LedgerAccount struct {
+	ID        uint        `gorm:"primaryKey"`
+	Kind      AccountKind `gorm:"index"`
+	UserID    *uint       `gorm:"uniqueIndex"`
+	Name      string
+	Balance   int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type JournalEntry struct {
+	ID            uint `gorm:"primaryKey"`
+	Type          TransactionType
+	TransactionID *uint `gorm:"index"`
+	Memo          string
+	Postings      []Posting `gorm:"foreignKey:EntryID"`
+	CreatedAt     time.Time
+}
+
+// Posting é um lançamento de débito (negativo) ou crédito (positivo) em uma conta.
+// Os postings de um mesmo JournalEntry sempre somam zero.
+type Posting struct {
+	ID        uint `gorm:"primaryKey"`
+	EntryID   uint `gorm:"index"`
+	AccountID uint `gorm:"index"`
+	Amount    int64
+	CreatedAt time.Time
+}
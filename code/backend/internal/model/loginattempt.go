@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// LoginAttempt conta as falhas de login recentes de uma chave ("account:<email>" ou "ip:<endereço>").
+type LoginAttempt struct {
+	Key           string    `gorm:"primaryKey"`
+	Failures      int
+	LastFailureAt time.Time `gorm:"index"`
+}
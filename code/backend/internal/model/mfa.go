@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// MFACredential guarda o segredo TOTP (cifrado) do usuário. A autenticação em dois
+// fatores só passa a ser exigida no login depois que ConfirmedAt é preenchido.
+type MFACredential struct {
+	UserID          uint `gorm:"primaryKey"`
+	SecretEncrypted string
+	// Último passo de tempo aceito; impede reutilizar o mesmo código
+	LastCounter int64
+	ConfirmedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RecoveryCode substitui o código TOTP uma única vez quando o autenticador é perdido.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	CodeHash  string `gorm:"uniqueIndex"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// MFAChallenge é emitido pelo login quando falta o segundo fator e vale por poucos minutos.
+type MFAChallenge struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"index"`
+	TokenHash string `gorm:"uniqueIndex"`
+	Attempts  int
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -8,6 +8,12 @@ const (
 	NotificationTypeRedeem      NotificationType = "redeem"
 	NotificationTypeReceiveCoins NotificationType = "receive_coins"
 	NotificationTypeDistribute   NotificationType = "distribute"
+	NotificationTypeReversal     NotificationType = "reversal"
+	NotificationTypeRefund       NotificationType = "refund"
+	NotificationTypeExpiration   NotificationType = "expiration"
+	NotificationTypeSecurity     NotificationType = "security"
+	NotificationTypeRestock      NotificationType = "restock"
+	NotificationTypeRewardLive   NotificationType = "reward_live"
 )
 
 type Notification struct {
@@ -13,4 +13,58 @@ type Reward struct {
     Category    string
     CreatedAt   time.Time
     UpdatedAt   time.Time
-}
\ No newline at end of file
+
+    // Moderação: vantagens suspensas por um administrador não aparecem nem podem ser resgatadas
+    SuspendedAt      *time.Time
+    SuspensionReason string
+
+    // Estoque: nil é ilimitado. Ao zerar a vantagem é desativada e SoldOutAt é preenchido
+    Stock     *uint
+    SoldOutAt *time.Time
+    // Limites de resgate por aluno; zero desliga. PeriodLimit vale para janelas de PeriodDays dias
+    MaxPerStudent uint
+    PeriodLimit   uint
+    PeriodDays    uint
+
+    // Promoções: a vantagem só aparece a partir de PublishAt e deixa de ser resgatável em
+    // ExpireAt; nulos não limitam. LaunchNotifiedAt marca o aviso de lançamento já enviado
+    PublishAt        *time.Time
+    ExpireAt         *time.Time
+    LaunchNotifiedAt *time.Time
+    // Validade dos cupons em dias a partir do resgate; zero usa só o ExpireAt da vantagem
+    CouponValidDays uint
+    // Política de cupons vencidos sem uso: devolver as moedas ao aluno
+    RefundOnExpiry bool
+}
+
+// RewardInstitution restringe uma vantagem às instituições escolhidas pela empresa.
+// Vantagens sem nenhuma linha aqui ficam visíveis para todas as instituições.
+type RewardInstitution struct {
+    RewardID      uint `gorm:"primaryKey"`
+    InstitutionID uint `gorm:"primaryKey;index"`
+}
+
+// RewardBranch restringe uma vantagem às filiais da empresa onde o cupom pode ser usado.
+// Vantagens sem nenhuma linha aqui valem em todas as filiais.
+type RewardBranch struct {
+    RewardID uint `gorm:"primaryKey"`
+    BranchID uint `gorm:"primaryKey;index"`
+}
+
+// RewardWaitlist guarda os alunos que pediram aviso quando uma vantagem esgotada
+// voltar ao estoque. NotifiedAt marca quem já foi avisado.
+type RewardWaitlist struct {
+    ID         uint `gorm:"primaryKey"`
+    RewardID   uint `gorm:"uniqueIndex:idx_waitlist_reward_student"`
+    StudentID  uint `gorm:"uniqueIndex:idx_waitlist_reward_student"`
+    CreatedAt  time.Time
+    NotifiedAt *time.Time
+}
+
+// RewardFavorite é a vantagem marcada pelo aluno; quem favorita uma vantagem agendada é
+// avisado quando ela for publicada.
+type RewardFavorite struct {
+    StudentID uint `gorm:"primaryKey"`
+    RewardID  uint `gorm:"primaryKey;index"`
+    CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Semester define um período de crédito. InstitutionID nulo vale para todas as
+// instituições que não possuem calendário próprio.
+type Semester struct {
+	ID            uint  `gorm:"primaryKey"`
+	InstitutionID *uint `gorm:"index"`
+	Name          string
+	StartsAt      time.Time
+	EndsAt        time.Time
+	CreatedAt     time.Time
+}
+
+// AllotmentPolicy define quantas moedas cada professor recebe por semestre. A
+// política mais específica (instituição + departamento, instituição, global) vence.
+type AllotmentPolicy struct {
+	ID            uint  `gorm:"primaryKey"`
+	InstitutionID *uint `gorm:"index"`
+	Department    *string
+	Amount        uint
+	// Accumulate mantém o saldo não utilizado; caso contrário ele é recolhido
+	// antes do novo crédito
+	Accumulate bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AllotmentCredit garante que cada professor seja creditado uma única vez por semestre.
+type AllotmentCredit struct {
+	ID            uint `gorm:"primaryKey"`
+	SemesterID    uint `gorm:"uniqueIndex:idx_allotment_semester_professor"`
+	ProfessorID   uint `gorm:"uniqueIndex:idx_allotment_semester_professor"`
+	PolicyID      uint
+	TransactionID *uint
+	Amount        uint
+	CreatedAt     time.Time
+}
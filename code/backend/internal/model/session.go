@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Session agrupa a família de refresh tokens emitidos a partir de um login.
+// Revogar a sessão invalida tanto os refresh tokens quanto os access tokens dela.
+type Session struct {
+	ID         string `gorm:"primaryKey"`
+	UserID     uint   `gorm:"index"`
+	UserAgent  string
+	IP         string
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+}
+
+// RefreshToken é rotacionado a cada uso; apresentar um token já usado revoga a sessão inteira.
+type RefreshToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	SessionID string `gorm:"index"`
+	TokenHash string `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// CompanySigningKey é o par Ed25519 com que os cupons da empresa são assinados. A chave
+// privada fica cifrada; a pública é publicada para o PDV conferir cupons offline. Chaves
+// aposentadas (RetiredAt) não assinam mais, mas continuam valendo para os cupons já emitidos.
+type CompanySigningKey struct {
+	ID                  uint   `gorm:"primaryKey"`
+	CompanyID           uint   `gorm:"index"`
+	KeyID               string `gorm:"uniqueIndex"`
+	PublicKey           string
+	PrivateKeyEncrypted string
+	CreatedAt           time.Time
+	RetiredAt           *time.Time
+}
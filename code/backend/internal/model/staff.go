@@ -0,0 +1,41 @@
+package model
+
+// StaffRole é o cargo de um funcionário dentro da empresa.
+type StaffRole string
+
+const (
+	StaffCashier StaffRole = "cashier"
+	StaffManager StaffRole = "manager"
+)
+
+// Permissões levadas no access token de empresas e funcionários.
+const (
+	PermValidateCoupons = "coupons:validate"
+	PermManageRewards   = "rewards:manage"
+	PermManageCompany   = "company:manage"
+)
+
+// Permissions lista o que o cargo permite; nil para cargos desconhecidos.
+func (r StaffRole) Permissions() []string {
+	switch r {
+	case StaffCashier:
+		return []string{PermValidateCoupons}
+	case StaffManager:
+		return []string{PermValidateCoupons, PermManageRewards}
+	}
+	return nil
+}
+
+// CompanyScope é a empresa em nome da qual o usuário age e suas permissões. A própria
+// empresa tem todas; funcionários, as do cargo. Demais papéis não têm escopo.
+func CompanyScope(user *User) (uint, []string) {
+	switch user.Role {
+	case CompanyRole:
+		return user.ID, []string{PermValidateCoupons, PermManageRewards, PermManageCompany}
+	case StaffUserRole:
+		if user.CompanyID != nil {
+			return *user.CompanyID, user.StaffRole.Permissions()
+		}
+	}
+	return 0, nil
+}
@@ -5,8 +5,14 @@ import "time"
 type TransactionType string
 
 const (
-    GiveCoins   TransactionType = "give"
-    RedeemCoins TransactionType = "redeem"
+    GiveCoins      TransactionType = "give"
+    RedeemCoins    TransactionType = "redeem"
+    OpeningBalance TransactionType = "opening"
+    Adjustment     TransactionType = "adjustment"
+    Reversal       TransactionType = "reversal"
+    Refund         TransactionType = "refund"
+    Expire         TransactionType = "expire"
+    AllotmentReset TransactionType = "reset"
 )
 
 type Transaction struct {
@@ -19,4 +25,5 @@ type Transaction struct {
     RewardID    *uint
     CreatedAt   time.Time
     Code        *string
+    ReversalOfID *uint           `gorm:"uniqueIndex"`
 }
\ No newline at end of file
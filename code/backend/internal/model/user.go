@@ -10,6 +10,9 @@ const (
     StudentRole   UserRole = "student"
     ProfessorRole UserRole = "professor"
     CompanyRole   UserRole = "company"
+    AdminRole     UserRole = "admin"
+    // Funcionário de uma empresa; age em nome dela com as permissões do seu cargo
+    StaffUserRole UserRole = "staff"
 )
 
 type User struct {
@@ -22,12 +25,23 @@ type User struct {
     RG           *string
     Address      string
     Registration *string
-    Institution  *string
+    // Instituição (tenant) do usuário; empresas não pertencem a nenhuma
+    InstitutionID *uint    `gorm:"index"`
     Course       *string
     Department   *string
     CompanyName  *string
+    // Empresa e cargo do funcionário (role staff)
+    CompanyID    *uint     `gorm:"index"`
+    StaffRole    StaffRole
     Balance      uint
     AvatarData   []byte    `gorm:"type:blob"`
+
+    // Preenchido quando um administrador desativa a conta; o login é bloqueado
+    DeactivatedAt   *time.Time
+    EmailVerifiedAt *time.Time
+    // Incrementado para invalidar de uma vez todos os access tokens do usuário
+    TokenVersion    uint
+
     CreatedAt    time.Time
     UpdatedAt    time.Time
 }
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type TokenPurpose string
+
+const (
+	EmailVerificationToken TokenPurpose = "verify_email"
+	PasswordResetToken     TokenPurpose = "reset_password"
+)
+
+// UserToken controla o uso único dos tokens assinados enviados por email.
+// A assinatura e a validade ficam no próprio token; aqui fica só o hash do nonce.
+type UserToken struct {
+	ID        uint         `gorm:"primaryKey"`
+	UserID    uint         `gorm:"index"`
+	Purpose   TokenPurpose `gorm:"index"`
+	NonceHash string       `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -33,5 +33,5 @@ func (r *companyRepository) Save(company *model.User) error {
 }
 
 func (r *companyRepository) Update(company *model.User) error {
-	return r.db.Save(company).Error
+	return r.db.Omit("Balance").Save(company).Error
 }
@@ -7,7 +7,7 @@ import (
 )
 
 type CouponRepository interface {
-	ListByStudent(studentID uint) ([]model.Coupon, error)
+	ListByStudent(studentID uint, status model.CouponStatus) ([]model.Coupon, error)
 	FindByCode(code string) (*model.Coupon, error)
 	FindByHash(hash string) (*model.Coupon, error)
 	Save(coupon *model.Coupon) error
@@ -21,9 +21,13 @@ func NewCouponRepository(db *gorm.DB) CouponRepository {
 	return &couponRepository{db}
 }
 
-func (r *couponRepository) ListByStudent(studentID uint) ([]model.Coupon, error) {
+func (r *couponRepository) ListByStudent(studentID uint, status model.CouponStatus) ([]model.Coupon, error) {
 	var coupons []model.Coupon
-	err := r.db.Where("student_id = ?", studentID).
+	query := r.db.Where("student_id = ?", studentID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.
 		Order("created_at desc").
 		Find(&coupons).Error
 	return coupons, err
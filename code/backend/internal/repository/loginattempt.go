@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"campuscash-backend/internal/model"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptStore guarda os contadores de falhas de login. Falhas mais antigas
+// que a janela informada não contam: o contador recomeça do zero.
+type LoginAttemptStore interface {
+	Get(key string) (*model.LoginAttempt, error)
+	RecordFailure(key string, now time.Time, window time.Duration) (*model.LoginAttempt, error)
+	Reset(key string) error
+	Prune(before time.Time) (int64, error)
+}
+
+type loginAttemptStore struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptStore persiste os contadores no banco, para que sobrevivam a
+// reinícios e sejam compartilhados entre réplicas.
+func NewLoginAttemptStore(db *gorm.DB) LoginAttemptStore {
+	return &loginAttemptStore{db}
+}
+
+func (r *loginAttemptStore) Get(key string) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	if err := r.db.Where("key = ?", key).First(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *loginAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (*model.LoginAttempt, error) {
+	var attempt model.LoginAttempt
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Upsert atômico: duas falhas simultâneas nunca se sobrescrevem
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures":        gorm.Expr("CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END", now.Add(-window)),
+				"last_failure_at": now,
+			}),
+		}).Create(&model.LoginAttempt{Key: key, Failures: 1, LastFailureAt: now}).Error; err != nil {
+			return err
+		}
+		return tx.Where("key = ?", key).First(&attempt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *loginAttemptStore) Reset(key string) error {
+	return r.db.Where("key = ?", key).Delete(&model.LoginAttempt{}).Error
+}
+
+func (r *loginAttemptStore) Prune(before time.Time) (int64, error) {
+	res := r.db.Where("last_failure_at < ?", before).Delete(&model.LoginAttempt{})
+	return res.RowsAffected, res.Error
+}
+
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]model.LoginAttempt
+}
+
+// NewMemoryLoginAttemptStore mantém os contadores em memória; serve para
+// desenvolvimento e instâncias únicas, e é zerado a cada reinício.
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string]model.LoginAttempt)}
+}
+
+func (r *memoryLoginAttemptStore) Get(key string) (*model.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempt, ok := r.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	return &attempt, nil
+}
+
+func (r *memoryLoginAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (*model.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempt, ok := r.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(now.Add(-window)) {
+		attempt = model.LoginAttempt{Key: key}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	r.attempts[key] = attempt
+	return &attempt, nil
+}
+
+func (r *memoryLoginAttemptStore) Reset(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *memoryLoginAttemptStore) Prune(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var removed int64
+	for key, attempt := range r.attempts {
+		if attempt.LastFailureAt.Before(before) {
+			delete(r.attempts, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -10,7 +10,6 @@ type ProfessorRepository interface {
 	FindByID(id uint) (*model.User, error)
 	Save(prof *model.User) error
 	Update(prof *model.User) error
-	FindAllStudents(institution string) ([]model.User, error)
 }
 
 type professorRepository struct {
@@ -34,19 +33,5 @@ func (r *professorRepository) Save(prof *model.User) error {
 }
 
 func (r *professorRepository) Update(prof *model.User) error {
-	return r.db.Save(prof).Error
-}
-
-func (r *professorRepository) FindAllStudents(institution string) ([]model.User, error) {
-	var students []model.User
-	var err error
-	
-	// Se institution for vazia, buscar todos os alunos
-	if institution == "" {
-		err = r.db.Where("role = ?", model.StudentRole).Find(&students).Error
-	} else {
-		err = r.db.Where("role = ? AND institution = ?", model.StudentRole, institution).Find(&students).Error
-	}
-	
-	return students, err
+	return r.db.Omit("Balance").Save(prof).Error
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"campuscash-backend/internal/model"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -11,7 +12,6 @@ type StudentRepository interface {
 	FindByID(id uint) (*model.User, error)
 	FindByEmail(email string) (*model.User, error)
 	Update(student *model.User) error
-	SearchByNameOrEmail(query string) ([]model.User, error)
 }
 
 type studentRepository struct {
@@ -36,21 +36,13 @@ func (r *studentRepository) FindByID(id uint) (*model.User, error) {
 
 func (r *studentRepository) FindByEmail(email string) (*model.User, error) {
 	var student model.User
-	if err := r.db.Where("email = ? AND role = ?", email, model.StudentRole).First(&student).Error; err != nil {
+	if err := r.db.Where("LOWER(email) = ? AND role = ?", strings.ToLower(email), model.StudentRole).First(&student).Error; err != nil {
 		return nil, err
 	}
 	return &student, nil
 }
 
 func (r *studentRepository) Update(student *model.User) error {
-	return r.db.Save(student).Error
-}
-
-func (r *studentRepository) SearchByNameOrEmail(query string) ([]model.User, error) {
-	var students []model.User
-	err := r.db.Where("role = ? AND (name ILIKE ? OR email ILIKE ?)", 
-		model.StudentRole, 
-		"%"+query+"%", 
-		"%"+query+"%").Find(&students).Error
-	return students, err
+	// Balance é projeção do livro-razão e só é alterado por ele
+	return r.db.Omit("Balance").Save(student).Error
 }
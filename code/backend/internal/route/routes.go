@@ -3,6 +3,7 @@ package route
 import (
 	"campuscash-backend/internal/controller"
 	"campuscash-backend/internal/middleware"
+	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/repository"
 	"campuscash-backend/internal/service"
 
@@ -10,7 +11,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
+func RegisterRoutes(r *gin.Engine, db *gorm.DB, scheduler *service.Scheduler, loginGuard *service.LoginGuard) {
 	studentRepo := repository.NewStudentRepository(db)
 	profRepo := repository.NewProfessorRepository(db)
 	rewardRepo := repository.NewRewardRepository(db)
@@ -20,22 +21,62 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 
 	studentSvc := service.NewStudentService(studentRepo, db)
 	profSvc := service.NewProfessorService(profRepo, studentRepo, db)
-	rewardSvc := service.NewRewardService(rewardRepo)
+	rewardSvc := service.NewRewardService(rewardRepo, db)
 	couponSvc := service.NewCouponService(couponRepo)
-	companySvc := service.NewCompanyService(companyRepo)
+	companySvc := service.NewCompanyService(companyRepo, db)
 	imgSvc := service.NewImageService()
 	notificationSvc := service.NewNotificationService(notificationRepo)
-	cronSvc := service.NewCronService(db, notificationSvc)
-
-	r.POST("/api/auth/login", controller.Login(db))
-	r.POST("/api/auth/signup/student", controller.SignupAluno(db))
-	r.POST("/api/auth/signup/company", controller.SignupCompany(db))
+	ledgerSvc := service.NewLedgerService(db)
+	reconciliationSvc := service.NewReconciliationService(db)
+	reversalSvc := service.NewReversalService(db, notificationSvc)
+	budgetSvc := service.NewBudgetService(db, notificationSvc)
+	adminSvc := service.NewAdminService(db)
+	invitationSvc := service.NewInvitationService(db)
+	sessionSvc := service.NewSessionService(db)
+	accountSvc := service.NewAccountService(db)
+	mfaSvc := service.NewMFAService(db)
+	classSvc := service.NewClassService(db)
+	tenantSvc := service.NewTenantService(db)
+	inventorySvc := service.NewInventoryService(db, notificationSvc)
+	launchSvc := service.NewRewardLaunchService(db, notificationSvc)
+	couponLifecycleSvc := service.NewCouponLifecycleService(db, notificationSvc)
+	couponSigningSvc := service.NewCouponSigningService(db)
+	voucherSvc := service.NewVoucherService(db)
+	couponValidationSvc := service.NewCouponValidationService(db)
+	staffSvc := service.NewStaffService(db)
+	branchSvc := service.NewBranchService(db)
+
+	middleware.SetTokenValidator(sessionSvc.Validate)
+
+	r.POST("/api/auth/login", controller.Login(db, sessionSvc, mfaSvc, loginGuard))
+	r.POST("/api/auth/login/mfa", controller.LoginMFA(sessionSvc, mfaSvc, loginGuard))
+	r.POST("/api/auth/login/mfa/setup", controller.LoginMFASetup(mfaSvc))
+	r.POST("/api/auth/refresh", controller.RefreshSession(sessionSvc))
+	r.POST("/api/auth/logout", middleware.Auth(), controller.Logout(sessionSvc))
+	r.POST("/api/auth/logout-all", middleware.Auth(), controller.LogoutAll(sessionSvc))
+	r.POST("/api/auth/verify-email", controller.VerifyEmail(accountSvc))
+	r.POST("/api/auth/verify-email/resend", controller.ResendVerification(accountSvc))
+	r.POST("/api/auth/forgot-password", controller.ForgotPassword(accountSvc))
+	r.POST("/api/auth/reset-password", controller.ResetPassword(accountSvc))
+	r.POST("/api/auth/signup/student", controller.SignupAluno(db, accountSvc))
+	r.POST("/api/auth/signup/company", controller.SignupCompany(db, accountSvc))
+	r.GET("/api/auth/signup/professor", controller.ProfessorInvitation(invitationSvc))
+	r.POST("/api/auth/signup/professor", controller.ActivateProfessor(invitationSvc))
 	r.GET("/api/auth/me", middleware.Auth(), controller.GetMe(db))
+	r.GET("/api/auth/mfa", middleware.Auth(), controller.MFAStatus(mfaSvc))
+	r.POST("/api/auth/mfa/enroll", middleware.Auth(), controller.MFAEnroll(mfaSvc))
+	r.POST("/api/auth/mfa/confirm", middleware.Auth(), controller.MFAConfirm(mfaSvc))
+	r.POST("/api/auth/mfa/recovery-codes", middleware.Auth(), controller.MFARegenerateRecoveryCodes(mfaSvc))
+	r.POST("/api/auth/mfa/disable", middleware.Auth(), controller.MFADisable(mfaSvc))
 
 
 	r.GET("/api/institutions", controller.ListInstitutions(db))
-	r.GET("/api/rewards", controller.ListRewards(db))
-	r.GET("/api/rewards/:id", controller.GetRewardById(db))
+	r.GET("/api/institution", middleware.Auth(), controller.MyInstitution(tenantSvc))
+	r.GET("/api/rewards", middleware.OptionalAuth(), controller.ListRewards(db))
+	r.GET("/api/rewards/nearby", middleware.OptionalAuth(), controller.NearbyRewards(branchSvc, db))
+	r.GET("/api/rewards/:id", middleware.OptionalAuth(), controller.GetRewardById(db))
+	r.GET("/api/companies/:id/keys", controller.CompanyPublicKeys(couponSigningSvc))
+	r.GET("/api/companies/:id/branches", controller.PublicCompanyBranches(branchSvc))
 
 
 	student := r.Group("/api/student", middleware.Auth("student"))
@@ -48,13 +89,22 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 
 
 		student.GET("/transactions", controller.StudentTransactions(db))
+		student.GET("/ledger", controller.LedgerStatement(ledgerSvc))
 
 
 
 
-		student.POST("/redeem", controller.StudentRedeem(db, notificationSvc))
+		student.POST("/redeem", middleware.Idempotency(db), controller.StudentRedeem(db, notificationSvc))
+		student.POST("/rewards/:id/waitlist", controller.JoinRewardWaitlist(inventorySvc))
+		student.DELETE("/rewards/:id/waitlist", controller.LeaveRewardWaitlist(inventorySvc))
+		student.GET("/favorites", controller.StudentFavorites(launchSvc))
+		student.POST("/rewards/:id/favorite", controller.AddRewardFavorite(launchSvc))
+		student.DELETE("/rewards/:id/favorite", controller.RemoveRewardFavorite(launchSvc))
 
 		student.GET("/coupons", controller.StudentCoupons(couponSvc, db))
+		student.POST("/coupons/:id/cancel", middleware.Idempotency(db), controller.StudentCancelCoupon(couponLifecycleSvc))
+		student.GET("/coupons/:id/qr", controller.StudentCouponQR(voucherSvc))
+		student.GET("/coupons/:id/voucher.pdf", controller.StudentCouponVoucher(voucherSvc))
 		student.GET("/notifications", controller.ListNotifications(notificationSvc))
 		student.PATCH("/notifications/read-all", controller.MarkAllNotificationsAsRead(notificationSvc))
 		student.PATCH("/notifications/:id/read", controller.MarkNotificationAsRead(notificationSvc))
@@ -70,10 +120,14 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 		professor.GET("/statistics", controller.ProfessorStatistics(profSvc))
 
 		professor.GET("/transactions", controller.ProfessorTransactions(db))
+		professor.GET("/ledger", controller.LedgerStatement(ledgerSvc))
+		professor.POST("/transactions/:id/reverse", middleware.Idempotency(db), controller.ProfessorReverseTransaction(reversalSvc))
 
 		professor.GET("/students", controller.ProfessorStudents(profSvc))
-		professor.GET("/students/search", controller.SearchStudents(studentSvc))
-		professor.POST("/give-coins", controller.GiveCoins(db, notificationSvc))
+		professor.GET("/students/search", controller.SearchStudents(profSvc))
+		professor.GET("/classes", controller.ProfessorClasses(classSvc))
+		professor.GET("/classes/:id/students", controller.ClassRoster(classSvc))
+		professor.POST("/give-coins", middleware.Idempotency(db), controller.GiveCoins(db, notificationSvc))
 		professor.GET("/notifications", controller.ListNotifications(notificationSvc))
 		professor.PATCH("/notifications/read-all", controller.MarkAllNotificationsAsRead(notificationSvc))
 		professor.PATCH("/notifications/:id/read", controller.MarkNotificationAsRead(notificationSvc))
@@ -81,37 +135,119 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 	}
 
 
-	company := r.Group("/api/company", middleware.Auth("company"))
+	// Empresa e funcionários: a empresa tem todas as permissões; caixas e gerentes só as
+	// do cargo (model.StaffRole)
+	company := r.Group("/api/company", middleware.Auth("company", "staff"))
 	{
-		company.GET("/profile", controller.CompanyProfile(companySvc))
-		company.PUT("/profile", controller.UpdateCompanyProfile(companySvc))
-		company.POST("/profile/logo", controller.UploadCompanyLogo(db, imgSvc))
-		company.GET("/statistics", controller.CompanyStatistics(companySvc, db))
-		company.GET("/validations", controller.CompanyValidations(companySvc))
-		company.GET("/rewards", controller.CompanyRewards(rewardSvc, db))
-		company.POST("/rewards", controller.CompanyCreateReward(rewardSvc))
-		company.POST("/rewards/:id/image", controller.UploadRewardImage(db, imgSvc))
-		company.PATCH("/rewards/:id", controller.CompanyUpdateReward(db))
-		company.PATCH("/rewards/:id/status", controller.CompanyUpdateRewardStatus(db))
-		company.DELETE("/rewards/:id", controller.CompanyDeleteReward(db))
-
-		company.GET("/history", controller.CompanyHistory(db))
-
-		company.POST("/validate-coupon", controller.CompanyValidateCoupon(couponSvc, db))
-		company.GET("/coupon/:hash", controller.GetCouponByHash(couponSvc, db))
+		company.GET("/branches", controller.CompanyBranches(branchSvc))
+
+		owner := company.Group("", middleware.RequirePermission(model.PermManageCompany))
+		owner.GET("/profile", controller.CompanyProfile(companySvc))
+		owner.PUT("/profile", controller.UpdateCompanyProfile(companySvc))
+		owner.POST("/profile/logo", controller.UploadCompanyLogo(db, imgSvc))
+		owner.GET("/statistics", controller.CompanyStatistics(companySvc, db))
+		owner.GET("/validations", controller.CompanyValidations(companySvc))
+
+		owner.GET("/history", controller.CompanyHistory(db))
+		owner.GET("/ledger", controller.LedgerStatement(ledgerSvc))
+		owner.POST("/history/:id/refund", middleware.Idempotency(db), controller.CompanyRefundRedemption(reversalSvc))
+		owner.POST("/keys/rotate", controller.CompanyRotateKey(couponSigningSvc))
+
+		owner.GET("/staff", controller.CompanyListStaff(staffSvc))
+		owner.POST("/staff", controller.CompanyCreateStaff(staffSvc))
+		owner.PATCH("/staff/:id/role", controller.CompanyChangeStaffRole(staffSvc))
+		owner.PATCH("/staff/:id/deactivate", controller.CompanySetStaffActive(staffSvc, false))
+		owner.PATCH("/staff/:id/activate", controller.CompanySetStaffActive(staffSvc, true))
+		owner.POST("/branches", controller.CompanyCreateBranch(branchSvc))
+		owner.PUT("/branches/:id", controller.CompanyUpdateBranch(branchSvc))
+		owner.DELETE("/branches/:id", controller.CompanyDeleteBranch(branchSvc))
+
+		rewards := company.Group("", middleware.RequirePermission(model.PermManageRewards))
+		rewards.GET("/rewards", controller.CompanyRewards(rewardSvc, db))
+		rewards.POST("/rewards", controller.CompanyCreateReward(rewardSvc))
+		rewards.POST("/rewards/:id/image", controller.UploadRewardImage(db, imgSvc))
+		rewards.PATCH("/rewards/:id", controller.CompanyUpdateReward(db))
+		rewards.PATCH("/rewards/:id/status", controller.CompanyUpdateRewardStatus(db))
+		rewards.PUT("/rewards/:id/stock", controller.CompanySetRewardStock(inventorySvc))
+		rewards.DELETE("/rewards/:id", controller.CompanyDeleteReward(db))
+
+		coupons := company.Group("", middleware.RequirePermission(model.PermValidateCoupons))
+		coupons.POST("/validate-coupon", controller.CompanyValidateCoupon(couponValidationSvc, db))
+		coupons.POST("/coupons/sync", controller.CompanySyncCoupons(couponSigningSvc))
+		coupons.GET("/coupon/:hash", controller.GetCouponByHash(couponValidationSvc, db))
 	}
 
 
 	r.GET("/api/images/:type/:id", controller.GetImage(db))
 
 
-	r.POST("/api/internal/cron/distribute-coins", controller.DistributeCoins(cronSvc))
+	admin := r.Group("/api/admin", middleware.Auth("admin"))
+	{
+		admin.GET("/users", controller.AdminListUsers(adminSvc))
+		admin.POST("/users", controller.AdminCreateUser(adminSvc))
+		admin.GET("/users/:id", controller.AdminGetUser(adminSvc))
+		admin.PATCH("/users/:id/deactivate", controller.AdminSetUserActive(adminSvc, false))
+		admin.PATCH("/users/:id/activate", controller.AdminSetUserActive(adminSvc, true))
+		admin.POST("/users/:id/reset-password", controller.AdminResetPassword(adminSvc))
+		admin.POST("/users/:id/unlock", controller.AdminUnlockUser(loginGuard))
+		admin.PATCH("/users/:id/role", controller.AdminChangeRole(adminSvc))
+		admin.DELETE("/users/:id/mfa", controller.AdminResetMFA(adminSvc))
+		admin.GET("/users/:id/ledger", controller.AdminUserLedger(ledgerSvc))
+
+		admin.POST("/professors/invite", controller.AdminInviteProfessor(invitationSvc))
+		admin.POST("/professors/import", controller.AdminImportProfessors(invitationSvc))
+		admin.POST("/professors/:id/resend-invitation", controller.AdminResendInvitation(invitationSvc))
+
+		admin.GET("/institutions", controller.AdminListInstitutions(adminSvc))
+		admin.POST("/institutions", controller.AdminCreateInstitution(adminSvc))
+		admin.PUT("/institutions/:id", controller.AdminUpdateInstitution(adminSvc))
+		admin.DELETE("/institutions/:id", controller.AdminDeleteInstitution(adminSvc))
+		admin.GET("/institutions/:id/settings", controller.AdminInstitutionSettings(tenantSvc))
+		admin.POST("/institutions/:id/semesters", controller.AdminCreateInstitutionSemester(budgetSvc))
+		admin.PUT("/institutions/:id/allotment-policy", controller.AdminSaveInstitutionPolicy(budgetSvc))
+
+		admin.GET("/courses", controller.AdminListCourses(classSvc))
+		admin.POST("/courses", controller.AdminCreateCourse(classSvc))
+		admin.DELETE("/courses/:id", controller.AdminDeleteCourse(classSvc))
+		admin.GET("/classes", controller.AdminListClasses(classSvc))
+		admin.POST("/classes", controller.AdminCreateClass(classSvc))
+		admin.DELETE("/classes/:id", controller.AdminDeleteClass(classSvc))
+		admin.GET("/classes/:id/students", controller.ClassRoster(classSvc))
+		admin.POST("/classes/:id/students", controller.AdminClassEnroll(classSvc))
+		admin.DELETE("/classes/:id/students/:studentId", controller.AdminClassUnenroll(classSvc))
+
+		admin.GET("/rewards", controller.AdminListRewards(adminSvc))
+		admin.PATCH("/rewards/:id/suspend", controller.AdminSuspendReward(adminSvc))
+		admin.PATCH("/rewards/:id/restore", controller.AdminRestoreReward(adminSvc))
+
+		admin.GET("/ledger/accounts", controller.AdminLedgerAccounts(adminSvc))
+		admin.GET("/ledger/entries", controller.AdminJournalEntries(adminSvc))
+		admin.GET("/ledger/verify", controller.AdminLedgerVerify(ledgerSvc))
+
+		admin.GET("/audit-log", controller.AdminAuditLog(adminSvc))
+
+		admin.GET("/jobs", controller.ListJobs(scheduler))
+		admin.GET("/jobs/:name/runs", controller.JobHistory(scheduler))
+		admin.POST("/jobs/:name/run", controller.AdminTriggerJob(scheduler))
+	}
+
 
+	internal := r.Group("/api/internal", middleware.InternalSecret())
+	{
+		internal.GET("/reconciliation", controller.ReconciliationReport(reconciliationSvc))
+		internal.POST("/reconciliation/repair", controller.ReconciliationRepair(reconciliationSvc))
+		internal.GET("/semesters", controller.ListSemesters(budgetSvc))
+		internal.POST("/semesters", controller.CreateSemester(budgetSvc))
+		internal.GET("/allotment-policies", controller.ListAllotmentPolicies(budgetSvc))
+		internal.PUT("/allotment-policies", controller.SaveAllotmentPolicy(budgetSvc))
+		internal.GET("/jobs", controller.ListJobs(scheduler))
+		internal.GET("/jobs/:name/runs", controller.JobHistory(scheduler))
+		internal.POST("/jobs/:name/run", controller.TriggerJob(scheduler))
+	}
 
 
 
 
-	cronSvc.StartCronJob()
 
 	r.GET("/", func(c *gin.Context) { c.String(200, "CampusCash Backend is running") })
 }
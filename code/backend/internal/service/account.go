@@ -0,0 +1,233 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/mail"
+	"campuscash-backend/pkg/validator"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAccountToken = apperror.New("invalid_account_token", http.StatusBadRequest)
+	ErrAccountTokenExpired = apperror.New("account_token_expired", http.StatusGone)
+)
+
+// signAccountToken gera "<payload>.<assinatura>", onde o payload carrega
+// finalidade, usuário, validade e um nonce aleatório.
+func signAccountToken(purpose model.TokenPurpose, userID uint, expiresAt time.Time, nonce string) string {
+	payload := fmt.Sprintf("%s:%d:%d:%s", purpose, userID, expiresAt.Unix(), nonce)
+	mac := hmac.New(sha256.New, config.JWTSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func parseAccountToken(token string, purpose model.TokenPurpose) (uint, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return 0, "", ErrInvalidAccountToken
+	}
+	payload, err1 := base64.RawURLEncoding.DecodeString(parts[0])
+	signature, err2 := base64.RawURLEncoding.DecodeString(parts[1])
+	if err1 != nil || err2 != nil {
+		return 0, "", ErrInvalidAccountToken
+	}
+	mac := hmac.New(sha256.New, config.JWTSecret)
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return 0, "", ErrInvalidAccountToken
+	}
+
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 4 || model.TokenPurpose(fields[0]) != purpose {
+		return 0, "", ErrInvalidAccountToken
+	}
+	userID, err1 := strconv.ParseUint(fields[1], 10, 64)
+	expires, err2 := strconv.ParseInt(fields[2], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, "", ErrInvalidAccountToken
+	}
+	if time.Now().Unix() > expires {
+		return 0, "", ErrAccountTokenExpired
+	}
+	return uint(userID), fields[3], nil
+}
+
+// AccountService cuida da verificação de email e da recuperação de senha.
+type AccountService struct {
+	db *gorm.DB
+}
+
+func NewAccountService(db *gorm.DB) *AccountService {
+	return &AccountService{db: db}
+}
+
+func (s *AccountService) issue(user *model.User, purpose model.TokenPurpose, ttl time.Duration) (string, error) {
+	nonce, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+	expiresAt := time.Now().Add(ttl)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Só o link mais recente de cada finalidade continua válido
+		if err := tx.Model(&model.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.UserToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			NonceHash: hashToken(nonce),
+			ExpiresAt: expiresAt,
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return signAccountToken(purpose, user.ID, expiresAt, nonce), nil
+}
+
+// consume valida o token e o marca como usado dentro da transação informada.
+func (s *AccountService) consume(tx *gorm.DB, token string, purpose model.TokenPurpose) (uint, error) {
+	userID, nonce, err := parseAccountToken(token, purpose)
+	if err != nil {
+		return 0, err
+	}
+	res := tx.Model(&model.UserToken{}).
+		Where("nonce_hash = ? AND user_id = ? AND purpose = ? AND used_at IS NULL", hashToken(nonce), userID, purpose).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return 0, ErrInvalidAccountToken
+	}
+	return userID, nil
+}
+
+func sendAccountMail(to, subject, body string) {
+	go func() {
+		if err := mail.SendMail(to, subject, body); err != nil {
+			log.Printf("Error sending %q to %s: %v", subject, to, err)
+		}
+	}()
+}
+
+func frontendLink(path, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", strings.TrimRight(config.FrontendURL, "/"), path, token)
+}
+
+// SendVerification envia o link de confirmação de email para uma conta recém-criada.
+func (s *AccountService) SendVerification(user *model.User) error {
+	ttl := time.Duration(config.EmailVerificationTTLHours) * time.Hour
+	token, err := s.issue(user, model.EmailVerificationToken, ttl)
+	if err != nil {
+		return err
+	}
+	sendAccountMail(user.Email, "Confirme seu email no CampusCash", fmt.Sprintf(
+		"Olá, %s!\n\nConfirme seu email pelo link abaixo (válido por %d horas):\n\n%s\n",
+		user.Name, config.EmailVerificationTTLHours, frontendLink("/verificar-email", token)))
+	return nil
+}
+
+// BackfillEmailVerification dá como verificados os usuários que já existiam antes da
+// verificação de email, para que REQUIRE_EMAIL_VERIFICATION não os bloqueie. Deve rodar
+// só na migração que cria a coluna email_verified_at.
+func BackfillEmailVerification(db *gorm.DB) error {
+	return db.Model(&model.User{}).
+		Where("email_verified_at IS NULL").
+		Update("email_verified_at", gorm.Expr("COALESCE(created_at, ?)", time.Now())).Error
+}
+
+// ResendVerification reenvia o link, sem revelar se o email está cadastrado.
+func (s *AccountService) ResendVerification(email string) error {
+	var user model.User
+	if err := s.db.Omit("AvatarData").Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.SendVerification(&user)
+}
+
+func (s *AccountService) VerifyEmail(token string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		userID, err := s.consume(tx, token, model.EmailVerificationToken)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).
+			Where("id = ? AND email_verified_at IS NULL", userID).
+			Update("email_verified_at", time.Now()).Error
+	})
+}
+
+// ForgotPassword envia o link de redefinição. Emails desconhecidos são ignorados
+// silenciosamente para não permitir enumeração de contas.
+func (s *AccountService) ForgotPassword(email string) error {
+	var user model.User
+	if err := s.db.Omit("AvatarData").Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.DeactivatedAt != nil {
+		return nil
+	}
+	ttl := time.Duration(config.PasswordResetTTLMinutes) * time.Minute
+	token, err := s.issue(&user, model.PasswordResetToken, ttl)
+	if err != nil {
+		return err
+	}
+	sendAccountMail(user.Email, "Redefinição de senha CampusCash", fmt.Sprintf(
+		"Olá, %s!\n\nRecebemos um pedido para redefinir sua senha. Use o link abaixo (válido por %d minutos):\n\n%s\n\n"+
+			"Se não foi você, ignore este email.\n",
+		user.Name, config.PasswordResetTTLMinutes, frontendLink("/redefinir-senha", token)))
+	return nil
+}
+
+// ResetPassword troca a senha e encerra todas as sessões abertas do usuário.
+func (s *AccountService) ResetPassword(token, password string) error {
+	var errs validator.Errors
+	if errs.Password("password", password); len(errs) > 0 {
+		return errs
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		userID, err := s.consume(tx, token, model.PasswordResetToken)
+		if err != nil {
+			return err
+		}
+		// Quem recebeu o link também comprovou ser dono do email
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password_hash":     string(hash),
+			"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+		}).Error; err != nil {
+			return err
+		}
+		return revokeUserSessions(tx, userID)
+	})
+}
@@ -0,0 +1,469 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound          = apperror.New("user_not_found", http.StatusNotFound)
+	ErrInvalidRole           = apperror.New("invalid_role", http.StatusBadRequest)
+	ErrEmailInUse            = apperror.New("email_in_use", http.StatusConflict)
+	ErrSelfModification      = apperror.New("self_modification", http.StatusForbidden)
+	ErrRoleChangeWithBalance = apperror.New("role_change_with_balance", http.StatusConflict)
+	ErrInstitutionNotFound   = apperror.New("institution_not_found", http.StatusNotFound)
+	ErrInstitutionExists     = apperror.New("institution_exists", http.StatusConflict)
+	ErrInstitutionInUse      = apperror.New("institution_in_use", http.StatusConflict)
+	ErrRewardNotFound        = apperror.New("reward_not_found", http.StatusNotFound)
+)
+
+func validRole(role string) bool {
+	switch model.UserRole(role) {
+	case model.StudentRole, model.ProfessorRole, model.CompanyRole, model.AdminRole:
+		return true
+	}
+	return false
+}
+
+type AdminService struct {
+	db *gorm.DB
+}
+
+func NewAdminService(db *gorm.DB) *AdminService {
+	return &AdminService{db: db}
+}
+
+func toAdminUserDTO(u model.User) dto.AdminUserDTO {
+	return dto.AdminUserDTO{
+		ID:            u.ID,
+		Name:          u.Name,
+		Email:         u.Email,
+		Role:          string(u.Role),
+		Balance:       u.Balance,
+		InstitutionID: u.InstitutionID,
+		Department:    u.Department,
+		CompanyName:   u.CompanyName,
+		Active:        u.DeactivatedAt == nil,
+		Pending:       u.PasswordHash == "",
+		CreatedAt:     u.CreatedAt,
+	}
+}
+
+func (s *AdminService) findUser(tx *gorm.DB, id uint) (*model.User, error) {
+	var user model.User
+	if err := tx.Omit("AvatarData").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers busca usuários por nome ou email, com filtros opcionais de papel e instituição.
+func (s *AdminService) ListUsers(search, role string, institutionID *uint, limit, offset int) (*dto.AdminUserListDTO, error) {
+	query := s.db.Model(&model.User{})
+	if search != "" {
+		term := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", term, term)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if institutionID != nil {
+		query = query.Scopes(InTenant(*institutionID))
+	}
+
+	result := &dto.AdminUserListDTO{Users: []dto.AdminUserDTO{}}
+	if err := query.Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+	var users []model.User
+	if err := query.Omit("AvatarData").Order("id").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		result.Users = append(result.Users, toAdminUserDTO(u))
+	}
+	return result, nil
+}
+
+func (s *AdminService) GetUser(id uint) (*dto.AdminUserDTO, error) {
+	user, err := s.findUser(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	result := toAdminUserDTO(*user)
+	return &result, nil
+}
+
+func (s *AdminService) CreateUser(actorID uint, input dto.AdminUserCreateDTO) (*dto.AdminUserDTO, error) {
+	if !validRole(input.Role) {
+		return nil, ErrInvalidRole
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user := model.User{
+		Name:            input.Name,
+		Email:           input.Email,
+		PasswordHash:    string(hash),
+		Role:            model.UserRole(input.Role),
+		InstitutionID:   input.InstitutionID,
+		Department:      input.Department,
+		EmailVerifiedAt: &now,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.User{}).Where("LOWER(email) = ?", strings.ToLower(input.Email)).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmailInUse
+		}
+		if input.InstitutionID != nil {
+			if err := tx.First(&model.Institution{}, *input.InstitutionID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrInstitutionNotFound
+				}
+				return err
+			}
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if _, err := AccountForUser(tx, user.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "user.create", "user", &user.ID, map[string]interface{}{"email": user.Email, "role": user.Role})
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := toAdminUserDTO(user)
+	return &result, nil
+}
+
+// SetActive ativa ou desativa uma conta. Contas desativadas não conseguem fazer login.
+func (s *AdminService) SetActive(actorID, userID uint, active bool) (*dto.AdminUserDTO, error) {
+	if actorID == userID {
+		return nil, ErrSelfModification
+	}
+	var user *model.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = s.findUser(tx, userID); err != nil {
+			return err
+		}
+		action := "user.activate"
+		user.DeactivatedAt = nil
+		if !active {
+			action = "user.deactivate"
+			now := time.Now()
+			user.DeactivatedAt = &now
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).
+			Update("deactivated_at", user.DeactivatedAt).Error; err != nil {
+			return err
+		}
+		if !active {
+			if err := revokeUserSessions(tx, userID); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, actorID, action, "user", &userID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := toAdminUserDTO(*user)
+	return &result, nil
+}
+
+// ResetPassword troca a senha do usuário por uma senha temporária, retornada uma única vez.
+func (s *AdminService) ResetPassword(actorID, userID uint) (*dto.AdminPasswordResetDTO, error) {
+	password, err := randomToken(6)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.findUser(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).
+			Update("password_hash", string(hash)).Error; err != nil {
+			return err
+		}
+		if err := revokeUserSessions(tx, userID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "user.reset_password", "user", &userID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AdminPasswordResetDTO{TemporaryPassword: password}, nil
+}
+
+// ChangeRole altera o papel do usuário. Só é permitido com saldo zerado, pois
+// moedas de aluno e de professor têm regras (lotes, créditos) diferentes.
+func (s *AdminService) ChangeRole(actorID, userID uint, role string) (*dto.AdminUserDTO, error) {
+	if !validRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, ErrSelfModification
+	}
+	var user *model.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = s.findUser(tx, userID); err != nil {
+			return err
+		}
+		if user.Role == model.UserRole(role) {
+			return nil
+		}
+		if user.Balance > 0 {
+			return ErrRoleChangeWithBalance
+		}
+		previous := user.Role
+		user.Role = model.UserRole(role)
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Update("role", user.Role).Error; err != nil {
+			return err
+		}
+		// O papel vai no access token: força novo login
+		if err := revokeUserSessions(tx, userID); err != nil {
+			return err
+		}
+		kind := model.UserAccount
+		if user.Role == model.CompanyRole {
+			kind = model.CompanyAccount
+		}
+		if err := tx.Model(&model.LedgerAccount{}).Where("user_id = ?", userID).Update("kind", kind).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "user.change_role", "user", &userID, map[string]interface{}{"from": previous, "to": user.Role})
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := toAdminUserDTO(*user)
+	return &result, nil
+}
+
+func (s *AdminService) ListInstitutions() ([]model.Institution, error) {
+	var institutions []model.Institution
+	err := s.db.Order("name").Find(&institutions).Error
+	return institutions, err
+}
+
+func (s *AdminService) CreateInstitution(actorID uint, input dto.InstitutionSaveDTO) (*model.Institution, error) {
+	inst := model.Institution{
+		Name:                      strings.TrimSpace(input.Name),
+		CoinExpiryDays:            input.CoinExpiryDays,
+		AllowUnenrolledRecipients: input.AllowUnenrolledRecipients,
+		CoinName:                  strings.TrimSpace(input.CoinName),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Institution{}).Where("name = ?", inst.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrInstitutionExists
+		}
+		if err := tx.Create(&inst).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "institution.create", "institution", &inst.ID, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+// UpdateInstitution altera nome e configurações da instituição.
+func (s *AdminService) UpdateInstitution(actorID, id uint, input dto.InstitutionSaveDTO) (*model.Institution, error) {
+	var inst model.Institution
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&inst, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInstitutionNotFound
+			}
+			return err
+		}
+		name := strings.TrimSpace(input.Name)
+		if name != inst.Name {
+			var count int64
+			if err := tx.Model(&model.Institution{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrInstitutionExists
+			}
+		}
+		previous := inst
+		inst.Name = name
+		inst.CoinExpiryDays = input.CoinExpiryDays
+		inst.AllowUnenrolledRecipients = input.AllowUnenrolledRecipients
+		inst.CoinName = strings.TrimSpace(input.CoinName)
+		if err := tx.Save(&inst).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "institution.update", "institution", &inst.ID, map[string]interface{}{"before": previous, "after": inst})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &inst, nil
+}
+
+func (s *AdminService) DeleteInstitution(actorID, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var inst model.Institution
+		if err := tx.First(&inst, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInstitutionNotFound
+			}
+			return err
+		}
+		var users, semesters, policies, courses int64
+		tx.Model(&model.User{}).Scopes(InTenant(id)).Count(&users)
+		tx.Model(&model.Semester{}).Where("institution_id = ?", id).Count(&semesters)
+		tx.Model(&model.AllotmentPolicy{}).Where("institution_id = ?", id).Count(&policies)
+		tx.Model(&model.Course{}).Where("institution_id = ?", id).Count(&courses)
+		if users+semesters+policies+courses > 0 {
+			return ErrInstitutionInUse
+		}
+		if err := tx.Delete(&inst).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "institution.delete", "institution", &id, map[string]interface{}{"name": inst.Name})
+	})
+}
+
+// ListRewards lista todas as vantagens (inclusive inativas) para moderação.
+func (s *AdminService) ListRewards(status string, companyID *uint) ([]model.Reward, error) {
+	query := s.db.Omit("ImageData")
+	switch status {
+	case "suspended":
+		query = query.Where("suspended_at IS NOT NULL")
+	case "active":
+		query = query.Where("suspended_at IS NULL AND active = ?", true)
+	}
+	if companyID != nil {
+		query = query.Where("company_id = ?", *companyID)
+	}
+	var rewards []model.Reward
+	err := query.Order("id desc").Find(&rewards).Error
+	return rewards, err
+}
+
+// SuspendReward retira a vantagem do catálogo até que um administrador a restaure.
+func (s *AdminService) SuspendReward(actorID, id uint, reason string) (*model.Reward, error) {
+	return s.moderateReward(actorID, id, &reason)
+}
+
+func (s *AdminService) RestoreReward(actorID, id uint) (*model.Reward, error) {
+	return s.moderateReward(actorID, id, nil)
+}
+
+func (s *AdminService) moderateReward(actorID, id uint, reason *string) (*model.Reward, error) {
+	var rew model.Reward
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("ImageData").First(&rew, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRewardNotFound
+			}
+			return err
+		}
+		action := "reward.restore"
+		var details interface{}
+		rew.SuspendedAt = nil
+		rew.SuspensionReason = ""
+		if reason != nil {
+			action = "reward.suspend"
+			details = map[string]interface{}{"motivo": *reason}
+			now := time.Now()
+			rew.SuspendedAt = &now
+			rew.SuspensionReason = *reason
+		}
+		if err := tx.Model(&model.Reward{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"suspended_at":      rew.SuspendedAt,
+			"suspension_reason": rew.SuspensionReason,
+		}).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, action, "reward", &rew.ID, details)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rew, nil
+}
+
+func (s *AdminService) LedgerAccounts(kind string) ([]dto.LedgerAccountDTO, error) {
+	query := s.db.Model(&model.LedgerAccount{})
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var accounts []model.LedgerAccount
+	if err := query.Order("id").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	result := make([]dto.LedgerAccountDTO, 0, len(accounts))
+	for _, acc := range accounts {
+		result = append(result, dto.LedgerAccountDTO{
+			ID:      acc.ID,
+			Kind:    string(acc.Kind),
+			UserID:  acc.UserID,
+			Name:    acc.Name,
+			Balance: acc.Balance,
+		})
+	}
+	return result, nil
+}
+
+// JournalEntries lista lançamentos do diário com seus postings, opcionalmente
+// restritos aos que movimentam uma conta.
+func (s *AdminService) JournalEntries(accountID *uint, limit, offset int) ([]dto.JournalEntryDTO, error) {
+	query := s.db.Model(&model.JournalEntry{})
+	if accountID != nil {
+		query = query.Where("id IN (?)", s.db.Model(&model.Posting{}).Select("entry_id").Where("account_id = ?", *accountID))
+	}
+	var entries []model.JournalEntry
+	if err := query.Preload("Postings").Order("id desc").Limit(limit).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	result := make([]dto.JournalEntryDTO, 0, len(entries))
+	for _, e := range entries {
+		item := dto.JournalEntryDTO{
+			ID:            e.ID,
+			Type:          string(e.Type),
+			TransactionID: e.TransactionID,
+			Memo:          e.Memo,
+			Postings:      make([]dto.JournalPostingDTO, 0, len(e.Postings)),
+			CreatedAt:     e.CreatedAt,
+		}
+		for _, p := range e.Postings {
+			item.Postings = append(item.Postings, dto.JournalPostingDTO{AccountID: p.AccountID, Amount: p.Amount})
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+// recordAudit grava uma ação administrativa. Deve rodar na mesma transação da
+// alteração, para que não exista mudança sem registro (nem registro sem mudança).
+func recordAudit(tx *gorm.DB, actorID uint, action, targetType string, targetID *uint, details interface{}) error {
+	entry := model.AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		entry.Details = string(data)
+	}
+	return tx.Create(&entry).Error
+}
+
+type AuditFilter struct {
+	ActorID    *uint
+	Action     string
+	TargetType string
+	TargetID   *uint
+	Limit      int
+	Offset     int
+}
+
+func (s *AdminService) AuditLog(filter AuditFilter) ([]dto.AuditLogDTO, error) {
+	query := s.db.Model(&model.AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+
+	var entries []model.AuditLog
+	if err := query.Order("id desc").Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	actorIDs := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		actorIDs = append(actorIDs, e.ActorID)
+	}
+	names := make(map[uint]string)
+	if len(actorIDs) > 0 {
+		var actors []model.User
+		s.db.Select("id", "name").Where("id IN ?", actorIDs).Find(&actors)
+		for _, a := range actors {
+			names[a.ID] = a.Name
+		}
+	}
+
+	result := make([]dto.AuditLogDTO, 0, len(entries))
+	for _, e := range entries {
+		item := dto.AuditLogDTO{
+			ID:         e.ID,
+			ActorID:    e.ActorID,
+			ActorName:  names[e.ActorID],
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			CreatedAt:  e.CreatedAt,
+		}
+		if e.Details != "" {
+			item.Details = json.RawMessage(e.Details)
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
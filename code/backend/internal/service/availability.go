@@ -0,0 +1,136 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrRewardNotAvailable = apperror.New("reward_not_available", http.StatusConflict)
+
+// RewardsAvailableAt restringe uma consulta sobre rewards às vantagens dentro da janela
+// de publicação.
+func RewardsAvailableAt(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(rewards.publish_at IS NULL OR rewards.publish_at <= ?) AND (rewards.expire_at IS NULL OR rewards.expire_at > ?)", now, now)
+	}
+}
+
+// RewardsUpcomingAt são as vantagens agendadas que ainda não foram publicadas.
+func RewardsUpcomingAt(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("rewards.publish_at > ?", now)
+	}
+}
+
+// EnsureRewardAvailable recusa o resgate fora da janela da promoção.
+func EnsureRewardAvailable(reward *model.Reward, now time.Time) error {
+	if reward.PublishAt != nil && now.Before(*reward.PublishAt) {
+		return ErrRewardNotAvailable.WithKey("reward_not_available.upcoming").With("publishAt", *reward.PublishAt)
+	}
+	if reward.ExpireAt != nil && !now.Before(*reward.ExpireAt) {
+		return ErrRewardNotAvailable.WithKey("reward_not_available.expired")
+	}
+	return nil
+}
+
+// CouponExpiry é a validade do cupom emitido agora: CouponValidDays após o resgate,
+// limitada pelo fim da promoção. Nil quando a vantagem não define nenhum dos dois.
+func CouponExpiry(reward *model.Reward, now time.Time) *time.Time {
+	var expiry *time.Time
+	if reward.CouponValidDays > 0 {
+		t := now.AddDate(0, 0, int(reward.CouponValidDays))
+		expiry = &t
+	}
+	if reward.ExpireAt != nil && (expiry == nil || reward.ExpireAt.Before(*expiry)) {
+		t := *reward.ExpireAt
+		expiry = &t
+	}
+	return expiry
+}
+
+type RewardLaunchService struct {
+	db              *gorm.DB
+	notificationSvc *NotificationService
+}
+
+func NewRewardLaunchService(db *gorm.DB, notificationSvc *NotificationService) *RewardLaunchService {
+	return &RewardLaunchService{db: db, notificationSvc: notificationSvc}
+}
+
+func (s *RewardLaunchService) AddFavorite(studentID, rewardID uint) (*model.RewardFavorite, error) {
+	var reward model.Reward
+	if err := s.db.First(&reward, rewardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRewardNotFound
+		}
+		return nil, err
+	}
+	if reward.SuspendedAt != nil {
+		return nil, ErrRewardNotFound
+	}
+	if err := EnsureRewardVisibleTo(s.db, reward.ID, studentID); err != nil {
+		return nil, err
+	}
+	favorite := model.RewardFavorite{StudentID: studentID, RewardID: rewardID}
+	return &favorite, s.db.Where(favorite).FirstOrCreate(&favorite).Error
+}
+
+func (s *RewardLaunchService) RemoveFavorite(studentID, rewardID uint) error {
+	return s.db.Where("student_id = ? AND reward_id = ?", studentID, rewardID).
+		Delete(&model.RewardFavorite{}).Error
+}
+
+// Favorites lista as vantagens favoritadas pelo aluno, inclusive as ainda agendadas.
+func (s *RewardLaunchService) Favorites(studentID uint) ([]model.Reward, error) {
+	rewards := []model.Reward{}
+	err := s.db.Joins("JOIN reward_favorites ON reward_favorites.reward_id = rewards.id").
+		Where("reward_favorites.student_id = ? AND rewards.suspended_at IS NULL", studentID).
+		Order("reward_favorites.created_at DESC").
+		Find(&rewards).Error
+	return rewards, err
+}
+
+// NotifyLaunched avisa quem favoritou as vantagens agendadas que entraram no ar desde a
+// última execução. Cada vantagem é marcada antes dos avisos para não notificar duas vezes.
+func (s *RewardLaunchService) NotifyLaunched(now time.Time) (int, error) {
+	var rewards []model.Reward
+	err := s.db.Select("id", "title").
+		Where("publish_at IS NOT NULL AND publish_at <= ? AND launch_notified_at IS NULL", now).
+		Where("active = ? AND suspended_at IS NULL", true).
+		Where("expire_at IS NULL OR expire_at > ?", now).
+		Find(&rewards).Error
+	if err != nil {
+		return 0, err
+	}
+	notified := 0
+	for _, reward := range rewards {
+		res := s.db.Model(&model.Reward{}).
+			Where("id = ? AND launch_notified_at IS NULL", reward.ID).
+			Update("launch_notified_at", now)
+		if res.Error != nil {
+			return notified, res.Error
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		var studentIDs []uint
+		if err := s.db.Model(&model.RewardFavorite{}).Where("reward_id = ?", reward.ID).
+			Pluck("student_id", &studentIDs).Error; err != nil {
+			return notified, err
+		}
+		for _, studentID := range studentIDs {
+			if s.notificationSvc != nil {
+				_ = s.notificationSvc.CreateNotification(studentID, model.NotificationTypeRewardLive,
+					"Vantagem Disponível", fmt.Sprintf("A vantagem %s já pode ser resgatada", reward.Title))
+			}
+			notified++
+		}
+	}
+	return notified, nil
+}
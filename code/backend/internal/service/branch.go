@@ -0,0 +1,342 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBranchNotFound    = apperror.New("branch_not_found", http.StatusNotFound)
+	ErrBranchInUse       = apperror.New("branch_in_use", http.StatusConflict)
+	ErrBranchRequired    = apperror.New("branch_required", http.StatusBadRequest)
+	ErrRewardNotAtBranch = apperror.New("reward_not_at_branch", http.StatusBadRequest)
+)
+
+const earthRadiusKm = 6371.0
+
+// distanceKm é a distância em linha reta entre dois pontos pela fórmula de haversine.
+func distanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// SetRewardBranches substitui as filiais onde a vantagem vale; lista vazia a libera em
+// todas. Só filiais da própria empresa são aceitas.
+func SetRewardBranches(tx *gorm.DB, companyID, rewardID uint, branchIDs []uint) error {
+	ids := make([]uint, 0, len(branchIDs))
+	seen := make(map[uint]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		var found []uint
+		if err := tx.Model(&model.Branch{}).Where("id IN ? AND company_id = ?", ids, companyID).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		if len(found) != len(ids) {
+			for _, id := range ids {
+				if !containsUint(found, id) {
+					return ErrBranchNotFound.With("branchId", id)
+				}
+			}
+		}
+	}
+	if err := tx.Where("reward_id = ?", rewardID).Delete(&model.RewardBranch{}).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := tx.Create(&model.RewardBranch{RewardID: rewardID, BranchID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RewardBranchIDs devolve, por vantagem, as filiais às quais ela está restrita.
+func RewardBranchIDs(tx *gorm.DB, rewardIDs []uint) (map[uint][]uint, error) {
+	result := make(map[uint][]uint)
+	if len(rewardIDs) == 0 {
+		return result, nil
+	}
+	var rows []model.RewardBranch
+	if err := tx.Where("reward_id IN ?", rewardIDs).Order("branch_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.RewardID] = append(result[row.RewardID], row.BranchID)
+	}
+	return result, nil
+}
+
+// RewardBranches lista as filiais onde a vantagem pode ser usada: as da restrição ou,
+// sem restrição, todas as da empresa.
+func RewardBranches(tx *gorm.DB, reward *model.Reward) ([]model.Branch, error) {
+	query := tx.Where("company_id = ?", reward.CompanyID)
+	restricted, err := RewardBranchIDs(tx, []uint{reward.ID})
+	if err != nil {
+		return nil, err
+	}
+	if ids := restricted[reward.ID]; len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	var branches []model.Branch
+	err = query.Order("name").Find(&branches).Error
+	return branches, err
+}
+
+// ensureCouponBranch confere a filial informada no caixa: precisa ser da empresa e, se a
+// vantagem for restrita a filiais, estar entre elas.
+func ensureCouponBranch(tx *gorm.DB, coupon *model.Coupon, companyID uint, branchID *uint) error {
+	if branchID != nil {
+		var count int64
+		if err := tx.Model(&model.Branch{}).Where("id = ? AND company_id = ?", *branchID, companyID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrBranchNotFound.With("branchId", *branchID)
+		}
+	}
+	var allowed []uint
+	if err := tx.Model(&model.RewardBranch{}).Where("reward_id = ?", coupon.RewardID).Pluck("branch_id", &allowed).Error; err != nil {
+		return err
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	if branchID == nil {
+		return ErrBranchRequired
+	}
+	if !containsUint(allowed, *branchID) {
+		return ErrRewardNotAtBranch.With("branchId", *branchID)
+	}
+	return nil
+}
+
+// BranchStatistics conta os cupons validados em cada filial da empresa, no total e desde
+// since. Com tenant, só entram cupons de alunos da instituição.
+func BranchStatistics(db *gorm.DB, companyID uint, tenant *uint, since time.Time) ([]dto.BranchStatisticsDTO, error) {
+	var rows []dto.BranchStatisticsDTO
+	err := db.Raw(`
+		SELECT branches.id AS branch_id, branches.name AS name,
+			COUNT(v.coupon_id) AS validations,
+			COALESCE(SUM(CASE WHEN v.validated_at >= ? THEN 1 ELSE 0 END), 0) AS validations_month,
+			COALESCE(SUM(v.cost), 0) AS coins
+		FROM branches
+		LEFT JOIN (
+			SELECT coupon_validations.coupon_id, coupon_validations.branch_id, coupon_validations.validated_at, rewards.cost
+			FROM coupon_validations
+			JOIN coupons ON coupons.id = coupon_validations.coupon_id
+			LEFT JOIN rewards ON rewards.id = coupons.reward_id
+			WHERE coupon_validations.company_id = ?
+			AND (? IS NULL OR coupons.student_id IN (SELECT id FROM users WHERE institution_id = ?))
+		) v ON v.branch_id = branches.id
+		WHERE branches.company_id = ?
+		GROUP BY branches.id, branches.name
+		ORDER BY branches.name
+	`, since, companyID, tenant, tenant, companyID).Scan(&rows).Error
+	if rows == nil {
+		rows = []dto.BranchStatisticsDTO{}
+	}
+	return rows, err
+}
+
+// NearbyReward é uma vantagem resgatável perto do usuário, com a filial mais próxima
+// onde o cupom vale.
+type NearbyReward struct {
+	Reward     model.Reward
+	Branch     model.Branch
+	DistanceKm float64
+}
+
+// BranchService gerencia as filiais das empresas e a busca de vantagens por proximidade.
+type BranchService struct {
+	db *gorm.DB
+}
+
+func NewBranchService(db *gorm.DB) *BranchService {
+	return &BranchService{db: db}
+}
+
+func (s *BranchService) find(tx *gorm.DB, companyID, branchID uint) (*model.Branch, error) {
+	var branch model.Branch
+	if err := tx.Where("id = ? AND company_id = ?", branchID, companyID).First(&branch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBranchNotFound
+		}
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (s *BranchService) List(companyID uint) ([]model.Branch, error) {
+	var branches []model.Branch
+	err := s.db.Where("company_id = ?", companyID).Order("name").Find(&branches).Error
+	return branches, err
+}
+
+func (s *BranchService) Create(companyID uint, input dto.BranchSaveDTO) (*model.Branch, error) {
+	branch := model.Branch{CompanyID: companyID}
+	applyBranch(&branch, input)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&branch).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, companyID, "branch.create", "branch", &branch.ID, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (s *BranchService) Update(companyID, branchID uint, input dto.BranchSaveDTO) (*model.Branch, error) {
+	var branch *model.Branch
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if branch, err = s.find(tx, companyID, branchID); err != nil {
+			return err
+		}
+		previous := *branch
+		applyBranch(branch, input)
+		if err := tx.Save(branch).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, companyID, "branch.update", "branch", &branchID, map[string]interface{}{"before": previous, "after": branch})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return branch, nil
+}
+
+// Delete remove a filial. Vantagens restritas a ela precisam ser editadas antes, senão
+// ficariam liberadas em todas as filiais sem a empresa perceber.
+func (s *BranchService) Delete(companyID, branchID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		branch, err := s.find(tx, companyID, branchID)
+		if err != nil {
+			return err
+		}
+		var rewards int64
+		if err := tx.Model(&model.RewardBranch{}).Where("branch_id = ?", branchID).Count(&rewards).Error; err != nil {
+			return err
+		}
+		if rewards > 0 {
+			return ErrBranchInUse
+		}
+		if err := tx.Delete(branch).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, companyID, "branch.delete", "branch", &branchID, map[string]interface{}{"name": branch.Name})
+	})
+}
+
+func applyBranch(branch *model.Branch, input dto.BranchSaveDTO) {
+	branch.Name = input.Name
+	branch.Address = input.Address
+	branch.Latitude = *input.Latitude
+	branch.Longitude = *input.Longitude
+	branch.OpeningHours = input.OpeningHours
+}
+
+// Nearby busca as vantagens resgatáveis em filiais a até radiusKm do ponto, da mais
+// próxima para a mais distante. Uma caixa de latitude/longitude filtra as filiais no
+// banco e a distância exata é calculada aqui. Com scoped, só entram as vantagens
+// visíveis à instituição, como na listagem.
+func (s *BranchService) Nearby(lat, lng, radiusKm float64, institutionID *uint, scoped bool) ([]NearbyReward, error) {
+	latDelta := radiusKm / (earthRadiusKm * math.Pi / 180)
+	query := s.db.Select("branches.*").
+		Joins("JOIN users ON users.id = branches.company_id AND users.deactivated_at IS NULL").
+		Where("branches.latitude BETWEEN ? AND ?", lat-latDelta, lat+latDelta)
+	// Perto dos polos ou do antimeridiano a caixa de longitude não se fecha; fica só a de latitude
+	if cos := math.Cos(lat * math.Pi / 180); cos > 0.01 {
+		lngDelta := latDelta / cos
+		if lng-lngDelta >= -180 && lng+lngDelta <= 180 {
+			query = query.Where("branches.longitude BETWEEN ? AND ?", lng-lngDelta, lng+lngDelta)
+		}
+	}
+	var candidates []model.Branch
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	distances := make(map[uint]float64)
+	byCompany := make(map[uint][]model.Branch)
+	for _, b := range candidates {
+		d := distanceKm(lat, lng, b.Latitude, b.Longitude)
+		if d > radiusKm {
+			continue
+		}
+		distances[b.ID] = d
+		byCompany[b.CompanyID] = append(byCompany[b.CompanyID], b)
+	}
+	result := []NearbyReward{}
+	if len(byCompany) == 0 {
+		return result, nil
+	}
+	companyIDs := make([]uint, 0, len(byCompany))
+	for id := range byCompany {
+		companyIDs = append(companyIDs, id)
+	}
+
+	rewardQuery := s.db.Where("active = ? AND suspended_at IS NULL AND company_id IN ?", true, companyIDs).
+		Scopes(RewardsAvailableAt(time.Now()))
+	if scoped {
+		rewardQuery = rewardQuery.Scopes(RewardsVisibleTo(institutionID))
+	}
+	var rewards []model.Reward
+	if err := rewardQuery.Find(&rewards).Error; err != nil {
+		return nil, err
+	}
+	rewardIDs := make([]uint, len(rewards))
+	for i, r := range rewards {
+		rewardIDs[i] = r.ID
+	}
+	restricted, err := RewardBranchIDs(s.db, rewardIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reward := range rewards {
+		allowed := restricted[reward.ID]
+		var nearest *model.Branch
+		for i, b := range byCompany[reward.CompanyID] {
+			if len(allowed) > 0 && !containsUint(allowed, b.ID) {
+				continue
+			}
+			if nearest == nil || distances[b.ID] < distances[nearest.ID] {
+				nearest = &byCompany[reward.CompanyID][i]
+			}
+		}
+		if nearest == nil {
+			continue
+		}
+		result = append(result, NearbyReward{
+			Reward:     reward,
+			Branch:     *nearest,
+			DistanceKm: math.Round(distances[nearest.ID]*100) / 100,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].DistanceKm != result[j].DistanceKm {
+			return result[i].DistanceKm < result[j].DistanceKm
+		}
+		return result[i].Reward.ID < result[j].Reward.ID
+	})
+	return result, nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errAlreadyCredited = errors.New("professor já creditado neste semestre")
+
+var (
+	ErrInvalidSemesterPeriod = apperror.New("invalid_semester_period", http.StatusBadRequest)
+	ErrSemesterOverlap       = apperror.New("semester_overlap", http.StatusConflict)
+)
+
+type BudgetService struct {
+	db              *gorm.DB
+	notificationSvc *NotificationService
+}
+
+func NewBudgetService(db *gorm.DB, notificationSvc *NotificationService) *BudgetService {
+	return &BudgetService{db: db, notificationSvc: notificationSvc}
+}
+
+func (s *BudgetService) ListSemesters() ([]model.Semester, error) {
+	semesters := []model.Semester{}
+	err := s.db.Order("starts_at desc").Find(&semesters).Error
+	return semesters, err
+}
+
+func (s *BudgetService) CreateSemester(input dto.SemesterCreateDTO) (*model.Semester, error) {
+	if !input.EndsAt.After(input.StartsAt) {
+		return nil, ErrInvalidSemesterPeriod
+	}
+	if err := s.ensureInstitution(input.InstitutionID); err != nil {
+		return nil, err
+	}
+	var overlapping int64
+	q := s.db.Model(&model.Semester{}).Where("starts_at < ? AND ends_at > ?", input.EndsAt, input.StartsAt)
+	if input.InstitutionID == nil {
+		q = q.Where("institution_id IS NULL")
+	} else {
+		q = q.Where("institution_id = ?", *input.InstitutionID)
+	}
+	if err := q.Count(&overlapping).Error; err != nil {
+		return nil, err
+	}
+	if overlapping > 0 {
+		return nil, ErrSemesterOverlap
+	}
+	semester := &model.Semester{
+		InstitutionID: input.InstitutionID,
+		Name:          input.Name,
+		StartsAt:      input.StartsAt,
+		EndsAt:        input.EndsAt,
+	}
+	return semester, s.db.Create(semester).Error
+}
+
+func (s *BudgetService) ListPolicies() ([]model.AllotmentPolicy, error) {
+	policies := []model.AllotmentPolicy{}
+	err := s.db.Order("id").Find(&policies).Error
+	return policies, err
+}
+
+// SavePolicy cria ou substitui a política do escopo (instituição, departamento).
+func (s *BudgetService) SavePolicy(input dto.AllotmentPolicyCreateDTO) (*model.AllotmentPolicy, error) {
+	if err := s.ensureInstitution(input.InstitutionID); err != nil {
+		return nil, err
+	}
+	var policy model.AllotmentPolicy
+	q := s.db
+	if input.InstitutionID == nil {
+		q = q.Where("institution_id IS NULL")
+	} else {
+		q = q.Where("institution_id = ?", *input.InstitutionID)
+	}
+	if input.Department == nil {
+		q = q.Where("department IS NULL")
+	} else {
+		q = q.Where("department = ?", *input.Department)
+	}
+	if err := q.First(&policy).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	policy.InstitutionID = input.InstitutionID
+	policy.Department = input.Department
+	policy.Amount = input.Amount
+	policy.Accumulate = input.Accumulate
+	return &policy, s.db.Save(&policy).Error
+}
+
+// ensureInstitution recusa calendários e políticas de instituições inexistentes; nil é o escopo global.
+func (s *BudgetService) ensureInstitution(institutionID *uint) error {
+	if institutionID == nil {
+		return nil
+	}
+	var count int64
+	if err := s.db.Model(&model.Institution{}).Where("id = ?", *institutionID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrInstitutionNotFound
+	}
+	return nil
+}
+
+// CurrentSemester retorna o semestre vigente da instituição, ou o calendário global.
+func (s *BudgetService) CurrentSemester(institutionID *uint, now time.Time) (*model.Semester, error) {
+	var semester model.Semester
+	if institutionID != nil {
+		err := s.db.Where("institution_id = ? AND starts_at <= ? AND ends_at > ?", *institutionID, now, now).
+			First(&semester).Error
+		if err == nil {
+			return &semester, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	if err := s.db.Where("institution_id IS NULL AND starts_at <= ? AND ends_at > ?", now, now).
+		First(&semester).Error; err != nil {
+		return nil, err
+	}
+	return &semester, nil
+}
+
+func (s *BudgetService) policyFor(policies []model.AllotmentPolicy, institutionID *uint, department *string) *model.AllotmentPolicy {
+	var best *model.AllotmentPolicy
+	bestScore := -1
+	for i, p := range policies {
+		score := 0
+		if p.InstitutionID != nil {
+			if institutionID == nil || *p.InstitutionID != *institutionID {
+				continue
+			}
+			score += 2
+		}
+		if p.Department != nil {
+			if department == nil || *p.Department != *department {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best = &policies[i]
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// CreditCurrentPeriod credita cada professor de acordo com a política do seu
+// semestre vigente. É idempotente: rodar várias vezes no mesmo semestre, mesmo
+// após reinícios, credita cada professor apenas uma vez.
+func (s *BudgetService) CreditCurrentPeriod() (*dto.AllotmentRunDTO, error) {
+	now := time.Now()
+	run := &dto.AllotmentRunDTO{RanAt: now}
+
+	var professors []model.User
+	if err := s.db.Select("id", "name", "institution_id", "department").
+		Where("role = ?", model.ProfessorRole).
+		Find(&professors).Error; err != nil {
+		return nil, err
+	}
+	var policies []model.AllotmentPolicy
+	if err := s.db.Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	for _, professor := range professors {
+		institutionID := professor.InstitutionID
+		semester, err := s.CurrentSemester(institutionID, now)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Printf("Error finding semester for professor %d: %v", professor.ID, err)
+				run.Failed++
+			} else {
+				run.Skipped++
+			}
+			continue
+		}
+		policy := s.policyFor(policies, institutionID, professor.Department)
+		if policy == nil || policy.Amount == 0 {
+			run.Skipped++
+			continue
+		}
+
+		err = s.creditProfessor(professor.ID, semester, policy)
+		switch {
+		case errors.Is(err, errAlreadyCredited):
+			run.Skipped++
+		case err != nil:
+			log.Printf("Error crediting professor %d: %v", professor.ID, err)
+			run.Failed++
+		default:
+			run.Credited++
+			if s.notificationSvc != nil {
+				if err := s.notificationSvc.CreateNotification(
+					professor.ID,
+					model.NotificationTypeReceiveCoins,
+					"Moedas Recebidas",
+					fmt.Sprintf("Você recebeu %d moedas referentes ao semestre %s", policy.Amount, semester.Name),
+				); err != nil {
+					log.Printf("Error creating notification for professor %d: %v", professor.ID, err)
+				}
+			}
+		}
+	}
+
+	log.Printf("Semester allotment: %d credited, %d skipped, %d failed", run.Credited, run.Skipped, run.Failed)
+	return run, nil
+}
+
+func (s *BudgetService) creditProfessor(professorID uint, semester *model.Semester, policy *model.AllotmentPolicy) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		credit := model.AllotmentCredit{
+			SemesterID:  semester.ID,
+			ProfessorID: professorID,
+			PolicyID:    policy.ID,
+			Amount:      policy.Amount,
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&credit)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errAlreadyCredited
+		}
+
+		if !policy.Accumulate {
+			var professor model.User
+			if err := tx.Select("id", "balance").First(&professor, professorID).Error; err != nil {
+				return err
+			}
+			if professor.Balance > 0 {
+				reset := model.Transaction{
+					FromUserID: &professor.ID,
+					ToUserID:   nil,
+					Amount:     professor.Balance,
+					Message:    fmt.Sprintf("Saldo não utilizado recolhido no início do semestre %s", semester.Name),
+					Type:       model.AllotmentReset,
+				}
+				if err := RecordTransfer(tx, &reset); err != nil {
+					return err
+				}
+			}
+		}
+
+		t := model.Transaction{
+			FromUserID: nil,
+			ToUserID:   &professorID,
+			Amount:     policy.Amount,
+			Message:    fmt.Sprintf("Crédito semestral - %s", semester.Name),
+			Type:       model.GiveCoins,
+		}
+		if err := RecordTransfer(tx, &t); err != nil {
+			return err
+		}
+		return tx.Model(&credit).Update("transaction_id", t.ID).Error
+	})
+}
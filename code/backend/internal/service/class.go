@@ -0,0 +1,314 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrProfessorNotFound         = apperror.New("professor_not_found", http.StatusNotFound)
+	ErrCourseNotFound            = apperror.New("course_not_found", http.StatusNotFound)
+	ErrCourseExists              = apperror.New("course_exists", http.StatusConflict)
+	ErrCourseInUse               = apperror.New("course_in_use", http.StatusConflict)
+	ErrClassNotFound             = apperror.New("class_not_found", http.StatusNotFound)
+	ErrEnrollmentNotFound        = apperror.New("enrollment_not_found", http.StatusNotFound)
+	ErrProfessorNotInInstitution = apperror.New("professor_not_in_institution", http.StatusBadRequest)
+	ErrStudentNotInInstitution   = apperror.New("student_not_in_institution", http.StatusBadRequest)
+	ErrStudentNotEnrolled        = apperror.New("student_not_enrolled", http.StatusForbidden)
+)
+
+type ClassService struct {
+	db *gorm.DB
+}
+
+func NewClassService(db *gorm.DB) *ClassService {
+	return &ClassService{db: db}
+}
+
+// RewardableStudents monta a consulta dos alunos que o professor pode ver e premiar:
+// os matriculados nas turmas dele ou, se a instituição permitir, todos os alunos dela.
+func RewardableStudents(tx *gorm.DB, prof *model.User) (*gorm.DB, error) {
+	inst, err := institutionOf(tx, prof)
+	if err != nil {
+		return nil, err
+	}
+	query := tx.Model(&model.User{}).Where("users.role = ?", model.StudentRole)
+	if inst != nil && inst.AllowUnenrolledRecipients {
+		return query.Scopes(InTenant(inst.ID)), nil
+	}
+	enrolled := tx.Model(&model.Enrollment{}).Select("enrollments.student_id").
+		Joins("JOIN classes ON classes.id = enrollments.class_id").
+		Where("classes.professor_id = ?", prof.ID)
+	return query.Where("users.id IN (?)", enrolled), nil
+}
+
+// ensureRewardable recusa destinatários que não são alunos visíveis ao professor.
+func ensureRewardable(tx *gorm.DB, prof, student *model.User) error {
+	if student.Role != model.StudentRole {
+		return ErrStudentNotFound
+	}
+	query, err := RewardableStudents(tx, prof)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if err := query.Where("users.id = ?", student.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrStudentNotEnrolled
+	}
+	return nil
+}
+
+func (s *ClassService) ListCourses(institutionID *uint) ([]model.Course, error) {
+	courses := []model.Course{}
+	query := s.db.Order("code")
+	if institutionID != nil {
+		query = query.Where("institution_id = ?", *institutionID)
+	}
+	err := query.Find(&courses).Error
+	return courses, err
+}
+
+func (s *ClassService) CreateCourse(actorID uint, input dto.CourseCreateDTO) (*model.Course, error) {
+	course := model.Course{
+		InstitutionID: input.InstitutionID,
+		Code:          strings.ToUpper(strings.TrimSpace(input.Code)),
+		Name:          strings.TrimSpace(input.Name),
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&model.Institution{}, input.InstitutionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInstitutionNotFound
+			}
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.Course{}).Where("institution_id = ? AND code = ?", course.InstitutionID, course.Code).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCourseExists
+		}
+		if err := tx.Create(&course).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "course.create", "course", &course.ID, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+func (s *ClassService) DeleteCourse(actorID, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var course model.Course
+		if err := tx.First(&course, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCourseNotFound
+			}
+			return err
+		}
+		var classes int64
+		if err := tx.Model(&model.Class{}).Where("course_id = ?", id).Count(&classes).Error; err != nil {
+			return err
+		}
+		if classes > 0 {
+			return ErrCourseInUse
+		}
+		if err := tx.Delete(&course).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "course.delete", "course", &id, map[string]interface{}{"code": course.Code})
+	})
+}
+
+// ListClasses lista as turmas com a quantidade de alunos; filtros nulos são ignorados.
+func (s *ClassService) ListClasses(courseID, professorID *uint) ([]dto.ClassDTO, error) {
+	classes := []dto.ClassDTO{}
+	query := s.db.Table("classes").
+		Select("classes.id, classes.name, classes.course_id, courses.code AS course_code, courses.name AS course_name, " +
+			"classes.institution_id, classes.professor_id, users.name AS professor_name, classes.created_at, " +
+			"(SELECT COUNT(*) FROM enrollments WHERE enrollments.class_id = classes.id) AS students").
+		Joins("JOIN courses ON courses.id = classes.course_id").
+		Joins("JOIN users ON users.id = classes.professor_id").
+		Order("courses.code, classes.name")
+	if courseID != nil {
+		query = query.Where("classes.course_id = ?", *courseID)
+	}
+	if professorID != nil {
+		query = query.Where("classes.professor_id = ?", *professorID)
+	}
+	err := query.Scan(&classes).Error
+	return classes, err
+}
+
+// CreateClass abre uma turma; o professor precisa pertencer à instituição da disciplina.
+func (s *ClassService) CreateClass(actorID uint, input dto.ClassCreateDTO) (*model.Class, error) {
+	class := model.Class{CourseID: input.CourseID, ProfessorID: input.ProfessorID, Name: strings.TrimSpace(input.Name)}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var course model.Course
+		if err := tx.First(&course, input.CourseID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCourseNotFound
+			}
+			return err
+		}
+		var prof model.User
+		if err := tx.Omit("AvatarData").Where("id = ? AND role = ?", input.ProfessorID, model.ProfessorRole).
+			First(&prof).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProfessorNotFound
+			}
+			return err
+		}
+		inst, err := institutionOf(tx, &prof)
+		if err != nil {
+			return err
+		}
+		if inst == nil || inst.ID != course.InstitutionID {
+			return ErrProfessorNotInInstitution
+		}
+		class.InstitutionID = course.InstitutionID
+		if err := tx.Create(&class).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "class.create", "class", &class.ID, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+// DeleteClass remove a turma e as matrículas dela.
+func (s *ClassService) DeleteClass(actorID, id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		class, err := findClass(tx, id, 0)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("class_id = ?", id).Delete(&model.Enrollment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(class).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "class.delete", "class", &id, map[string]interface{}{"name": class.Name})
+	})
+}
+
+// findClass carrega a turma. Com professorID diferente de zero, turmas de outros
+// professores são tratadas como inexistentes; zero (administradores) acessa todas.
+func findClass(tx *gorm.DB, id, professorID uint) (*model.Class, error) {
+	var class model.Class
+	if err := tx.First(&class, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClassNotFound
+		}
+		return nil, err
+	}
+	if professorID != 0 && class.ProfessorID != professorID {
+		return nil, ErrClassNotFound
+	}
+	return &class, nil
+}
+
+func (s *ClassService) Roster(classID, professorID uint) ([]dto.RosterStudentDTO, error) {
+	class, err := findClass(s.db, classID, professorID)
+	if err != nil {
+		return nil, err
+	}
+	roster := []dto.RosterStudentDTO{}
+	err = s.db.Table("enrollments").
+		Select("users.id, users.name, users.email, users.registration, users.course, enrollments.created_at AS enrolled_at").
+		Joins("JOIN users ON users.id = enrollments.student_id").
+		Where("enrollments.class_id = ?", class.ID).
+		Order("users.name").
+		Scan(&roster).Error
+	return roster, err
+}
+
+// Enroll matricula alunos da mesma instituição da turma. Alunos já matriculados são
+// contados em Skipped; qualquer aluno inválido cancela a operação inteira. Só
+// administradores mexem nas matrículas: elas decidem quem o professor pode premiar.
+func (s *ClassService) Enroll(actorID, classID uint, studentIDs []uint) (*dto.EnrollmentResultDTO, error) {
+	result := &dto.EnrollmentResultDTO{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		class, err := findClass(tx, classID, 0)
+		if err != nil {
+			return err
+		}
+		var students []model.User
+		if err := tx.Omit("AvatarData").Where("id IN ? AND role = ?", studentIDs, model.StudentRole).
+			Find(&students).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]model.User, len(students))
+		for _, st := range students {
+			byID[st.ID] = st
+		}
+
+		ids := make([]uint, 0, len(studentIDs))
+		seen := make(map[uint]bool, len(studentIDs))
+		for _, id := range studentIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			st, ok := byID[id]
+			if !ok {
+				return ErrStudentNotFound.With("studentId", id)
+			}
+			if st.InstitutionID == nil || *st.InstitutionID != class.InstitutionID {
+				return ErrStudentNotInInstitution.With("studentId", id)
+			}
+			ids = append(ids, id)
+		}
+
+		for _, id := range ids {
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&model.Enrollment{ClassID: class.ID, StudentID: id})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				result.Skipped++
+			} else {
+				result.Enrolled++
+			}
+		}
+		return recordAudit(tx, actorID, "class.enroll", "class", &class.ID, map[string]interface{}{"studentIds": ids})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ClassService) Unenroll(actorID, classID, studentID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		class, err := findClass(tx, classID, 0)
+		if err != nil {
+			return err
+		}
+		res := tx.Where("class_id = ? AND student_id = ?", class.ID, studentID).Delete(&model.Enrollment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrEnrollmentNotFound
+		}
+		return recordAudit(tx, actorID, "class.unenroll", "class", &class.ID, map[string]interface{}{"studentId": studentID})
+	})
+}
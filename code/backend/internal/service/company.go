@@ -12,16 +12,17 @@ import (
 type CompanyService interface {
 	GetProfile(id uint) (*dto.CompanyProfileDTO, error)
 	UpdateProfile(id uint, input dto.CompanyUpdateDTO) (*dto.CompanyProfileDTO, error)
-	GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatisticsDTO, error)
-	GetValidations(id uint) ([]dto.CompanyValidationDTO, error)
+	GetStatistics(id uint, institutionID *uint, db *gorm.DB) (*dto.CompanyStatisticsDTO, error)
+	GetValidations(id uint, limit, offset int) ([]dto.CompanyValidationDTO, error)
 }
 
 type companyService struct {
 	repo repository.CompanyRepository
+	db   *gorm.DB
 }
 
-func NewCompanyService(repo repository.CompanyRepository) CompanyService {
-	return &companyService{repo}
+func NewCompanyService(repo repository.CompanyRepository, db *gorm.DB) CompanyService {
+	return &companyService{repo, db}
 }
 
 func (s *companyService) GetProfile(id uint) (*dto.CompanyProfileDTO, error) {
@@ -31,12 +32,12 @@ func (s *companyService) GetProfile(id uint) (*dto.CompanyProfileDTO, error) {
 	}
 	return &dto.CompanyProfileDTO{
 		ID:       comp.ID,
-		Name:     *comp.CompanyName,
+		Name:     stringValue(comp.CompanyName),
 		Email:    comp.Email,
 		Category: "TODO", // set actual if present
 		Phone:    "TODO", // set actual if present
 		Address:  comp.Address,
-		CNPJ:     *comp.CPF,
+		CNPJ:     stringValue(comp.CPF),
 	}, nil
 }
 
@@ -45,7 +46,9 @@ func (s *companyService) UpdateProfile(id uint, input dto.CompanyUpdateDTO) (*dt
 	if err != nil {
 		return nil, err
 	}
-	
+	if err := EnsureUniqueUser(s.db, id, input.Email, input.CNPJ, "cnpj"); err != nil {
+		return nil, err
+	}
 
 	if input.Name != "" {
 		company.CompanyName = &input.Name
@@ -61,21 +64,47 @@ func (s *companyService) UpdateProfile(id uint, input dto.CompanyUpdateDTO) (*dt
 	}
 	
 	if err := s.repo.Update(company); err != nil {
-		return nil, err
+		return nil, UniqueViolation(err, "cnpj")
 	}
 	
 	return &dto.CompanyProfileDTO{
 		ID:       company.ID,
-		Name:     *company.CompanyName,
+		Name:     stringValue(company.CompanyName),
 		Email:    company.Email,
 		Category: "TODO", // set actual if present
 		Phone:    "TODO", // set actual if present
 		Address:  company.Address,
-		CNPJ:     *company.CPF,
+		CNPJ:     stringValue(company.CPF),
 	}, nil
 }
 
-func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatisticsDTO, error) {
+// GetStatistics calcula as métricas da empresa. Com uma instituição (a da própria
+// empresa ou a escolhida no filtro), só contam as vantagens visíveis a ela e os
+// resgates feitos por alunos dela.
+func (s *companyService) GetStatistics(id uint, institutionID *uint, db *gorm.DB) (*dto.CompanyStatisticsDTO, error) {
+	company, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	tenant := institutionID
+	if company.InstitutionID != nil {
+		tenant = company.InstitutionID
+	}
+	rewards := func() *gorm.DB {
+		q := db.Model(&model.Reward{})
+		if tenant != nil {
+			q = q.Scopes(RewardsVisibleTo(tenant))
+		}
+		return q
+	}
+	redemptions := func() *gorm.DB {
+		q := db.Model(&model.Transaction{})
+		if tenant != nil {
+			q = q.Where("from_user_id IN (?)", db.Model(&model.User{}).Select("users.id").Scopes(InTenant(*tenant)))
+		}
+		return q
+	}
+
 	// Calcular início do mês atual e mês anterior
 	now := time.Now()
 	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
@@ -83,31 +112,31 @@ func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatis
 
 	// Vantagens Ativas
 	var vantagensAtivas int64
-	db.Model(&model.Reward{}).
+	rewards().
 		Where("company_id = ? AND active = ?", id, true).
 		Count(&vantagensAtivas)
 
 	// Vantagens Ativas no mês anterior
 	var vantagensAtivasLastMonth int64
-	db.Model(&model.Reward{}).
+	rewards().
 		Where("company_id = ? AND active = ? AND created_at < ?", id, true, startOfMonth).
 		Count(&vantagensAtivasLastMonth)
 
 	// Total de Vantagens
 	var totalVantagens int64
-	db.Model(&model.Reward{}).
+	rewards().
 		Where("company_id = ?", id).
 		Count(&totalVantagens)
 
 	// Resgates Este Mês
 	var resgatesMes int64
-	db.Model(&model.Transaction{}).
+	redemptions().
 		Where("to_user_id = ? AND type = ? AND created_at >= ?", id, model.RedeemCoins, startOfMonth).
 		Count(&resgatesMes)
 
 	// Resgates Mês Anterior
 	var resgatesLastMonth int64
-	db.Model(&model.Transaction{}).
+	redemptions().
 		Where("to_user_id = ? AND type = ? AND created_at >= ? AND created_at < ?", id, model.RedeemCoins, startOfLastMonth, startOfMonth).
 		Count(&resgatesLastMonth)
 
@@ -116,7 +145,7 @@ func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatis
 	var receitaResult struct {
 		Total uint
 	}
-	db.Model(&model.Transaction{}).
+	redemptions().
 		Select("COALESCE(SUM(amount), 0) as total").
 		Where("to_user_id = ? AND type = ? AND created_at >= ?", id, model.RedeemCoins, startOfMonth).
 		Scan(&receitaResult)
@@ -127,7 +156,7 @@ func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatis
 	var receitaLastMonthResult struct {
 		Total uint
 	}
-	db.Model(&model.Transaction{}).
+	redemptions().
 		Select("COALESCE(SUM(amount), 0) as total").
 		Where("to_user_id = ? AND type = ? AND created_at >= ? AND created_at < ?", id, model.RedeemCoins, startOfLastMonth, startOfMonth).
 		Scan(&receitaLastMonthResult)
@@ -141,7 +170,8 @@ func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatis
 		SELECT COUNT(DISTINCT from_user_id) as count 
 		FROM transactions 
 		WHERE to_user_id = ? AND type = ? AND from_user_id IS NOT NULL
-	`, id, model.RedeemCoins).Scan(&alunosUnicosResult)
+		AND (? IS NULL OR from_user_id IN (SELECT id FROM users WHERE institution_id = ?))
+	`, id, model.RedeemCoins, tenant, tenant).Scan(&alunosUnicosResult)
 
 	// Alunos Únicos no mês anterior
 	var alunosUnicosLastMonthResult struct {
@@ -151,14 +181,18 @@ func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatis
 		SELECT COUNT(DISTINCT from_user_id) as count 
 		FROM transactions 
 		WHERE to_user_id = ? AND type = ? AND from_user_id IS NOT NULL AND created_at < ?
-	`, id, model.RedeemCoins, startOfMonth).Scan(&alunosUnicosLastMonthResult)
+		AND (? IS NULL OR from_user_id IN (SELECT id FROM users WHERE institution_id = ?))
+	`, id, model.RedeemCoins, startOfMonth, tenant, tenant).Scan(&alunosUnicosLastMonthResult)
 
 	// Resgates Pendentes (cupons não utilizados)
 	var resgatesPendentes int64
-	db.Model(&model.Coupon{}).
+	pendentesQuery := db.Model(&model.Coupon{}).
 		Joins("JOIN rewards ON coupons.reward_id = rewards.id").
-		Where("rewards.company_id = ? AND coupons.redeemed = ?", id, false).
-		Count(&resgatesPendentes)
+		Where("rewards.company_id = ? AND coupons.status = ?", id, model.CouponIssued)
+	if tenant != nil {
+		pendentesQuery = pendentesQuery.Where("coupons.student_id IN (?)", db.Model(&model.User{}).Select("users.id").Scopes(InTenant(*tenant)))
+	}
+	pendentesQuery.Count(&resgatesPendentes)
 
 	// Calcular percentuais
 	calcPercentual := func(atual, anterior int64) float64 {
@@ -187,6 +221,11 @@ func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatis
 
 	alunosUnicosPercentual := calcPercentual(alunosUnicosResult.Count, alunosUnicosLastMonthResult.Count)
 
+	filiais, err := BranchStatistics(db, id, tenant, startOfMonth)
+	if err != nil {
+		return nil, err
+	}
+
 	return &dto.CompanyStatisticsDTO{
 		VantagensAtivas:          uint(vantagensAtivas),
 		ResgatesMes:              uint(resgatesMes),
@@ -198,30 +237,60 @@ func (s *companyService) GetStatistics(id uint, db *gorm.DB) (*dto.CompanyStatis
 		ResgatesMesPercentual:     resgatesMesPercentual,
 		ReceitaMoedasPercentual:   receitaMoedasPercentual,
 		AlunosUnicosPercentual:    alunosUnicosPercentual,
+		Filiais:                   filiais,
 	}, nil
 }
 
-func (s *companyService) GetValidations(id uint) ([]dto.CompanyValidationDTO, error) {
-
-
-	return []dto.CompanyValidationDTO{
-		{
-			ID:       1,
-			Codigo:   "CC-2024-001",
-			Aluno:    "João Silva Santos",
-			Vantagem: "Desconto 30% Livraria Central",
-			Data:     "2024-01-15T10:30:00",
-			Status:   "validado",
-			Valor:    200,
-		},
-		{
-			ID:       2,
-			Codigo:   "CC-2024-002",
-			Aluno:    "Maria Santos Costa",
-			Vantagem: "Almoço Grátis Restaurante",
-			Data:     "2024-01-14T14:20:00",
-			Status:   "pendente",
-			Valor:    150,
-		},
-	}, nil
+// GetValidations lista as baixas de cupons da empresa, mais recentes primeiro, com o
+// funcionário que validou.
+func (s *companyService) GetValidations(id uint, limit, offset int) ([]dto.CompanyValidationDTO, error) {
+	var rows []struct {
+		ID            uint
+		Code          string
+		StudentName   string
+		RewardTitle   string
+		Cost          uint
+		ValidatedAt   time.Time
+		ValidatorID   uint
+		ValidatorName string
+		Channel       string
+		Location      string
+		BranchID      *uint
+		BranchName    string
+	}
+	err := s.db.Table("coupon_validations").
+		Select("coupon_validations.id, coupons.code, students.name AS student_name, rewards.title AS reward_title, rewards.cost, "+
+			"coupon_validations.validated_at, coupon_validations.validated_by AS validator_id, validators.name AS validator_name, "+
+			"coupon_validations.channel, coupon_validations.location, coupon_validations.branch_id, branches.name AS branch_name").
+		Joins("JOIN coupons ON coupons.id = coupon_validations.coupon_id").
+		Joins("LEFT JOIN rewards ON rewards.id = coupons.reward_id").
+		Joins("LEFT JOIN users AS students ON students.id = coupons.student_id").
+		Joins("LEFT JOIN users AS validators ON validators.id = coupon_validations.validated_by").
+		Joins("LEFT JOIN branches ON branches.id = coupon_validations.branch_id").
+		Where("coupon_validations.company_id = ?", id).
+		Order("coupon_validations.validated_at DESC").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dto.CompanyValidationDTO, len(rows))
+	for i, r := range rows {
+		result[i] = dto.CompanyValidationDTO{
+			ID:            r.ID,
+			Codigo:        r.Code,
+			Aluno:         r.StudentName,
+			Vantagem:      r.RewardTitle,
+			Data:          r.ValidatedAt.Format("2006-01-02T15:04:05"),
+			Status:        "validado",
+			Valor:         r.Cost,
+			ValidadoPorID: r.ValidatorID,
+			ValidadoPor:   r.ValidatorName,
+			Canal:         r.Channel,
+			Local:         r.Location,
+			FilialID:      r.BranchID,
+			Filial:        r.BranchName,
+		}
+	}
+	return result, nil
 }
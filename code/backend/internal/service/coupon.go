@@ -3,15 +3,12 @@ package service
 import (
 	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/repository"
-	"time"
 )
 
 type CouponService interface {
-	ListStudentCoupons(studentID uint) ([]model.Coupon, error)
+	ListStudentCoupons(studentID uint, status model.CouponStatus) ([]model.Coupon, error)
 	ValidateCoupon(code string) (*model.Coupon, error)
 	ValidateCouponByHash(hash string) (*model.Coupon, error)
-	UseCoupon(code string) error
-	UseCouponByHash(hash string) error
 }
 
 type couponService struct {
@@ -22,8 +19,8 @@ func NewCouponService(repo repository.CouponRepository) CouponService {
 	return &couponService{repo}
 }
 
-func (s *couponService) ListStudentCoupons(studentID uint) ([]model.Coupon, error) {
-	return s.repo.ListByStudent(studentID)
+func (s *couponService) ListStudentCoupons(studentID uint, status model.CouponStatus) ([]model.Coupon, error) {
+	return s.repo.ListByStudent(studentID, status)
 }
 
 func (s *couponService) ValidateCoupon(code string) (*model.Coupon, error) {
@@ -33,25 +30,3 @@ func (s *couponService) ValidateCoupon(code string) (*model.Coupon, error) {
 func (s *couponService) ValidateCouponByHash(hash string) (*model.Coupon, error) {
 	return s.repo.FindByHash(hash)
 }
-
-func (s *couponService) UseCoupon(code string) error {
-	coupon, err := s.repo.FindByCode(code)
-	if err != nil {
-		return err
-	}
-	now := time.Now()
-	coupon.UsedAt = &now
-	coupon.Redeemed = true
-	return s.repo.Save(coupon)
-}
-
-func (s *couponService) UseCouponByHash(hash string) error {
-	coupon, err := s.repo.FindByHash(hash)
-	if err != nil {
-		return err
-	}
-	now := time.Now()
-	coupon.UsedAt = &now
-	coupon.Redeemed = true
-	return s.repo.Save(coupon)
-}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCouponNotFound  = apperror.New("coupon_not_found", http.StatusNotFound)
+	ErrCouponExpired   = apperror.New("coupon_expired", http.StatusBadRequest)
+	ErrCouponRefunded  = apperror.New("coupon_refunded", http.StatusBadRequest)
+	ErrCouponCancelled = apperror.New("coupon_cancelled", http.StatusBadRequest)
+)
+
+// couponStateError explica por que um cupom fora do estado "issued" não pode seguir.
+func couponStateError(status model.CouponStatus) error {
+	switch status {
+	case model.CouponExpired:
+		return ErrCouponExpired
+	case model.CouponRefunded:
+		return ErrCouponRefunded
+	case model.CouponCancelled:
+		return ErrCouponCancelled
+	}
+	return ErrCouponAlreadyUsed
+}
+
+// transitionCoupon move o cupom para o estado to, desde que ele esteja em um dos estados
+// from. A condição vai no UPDATE, então transições concorrentes não se sobrepõem. A baixa
+// (estado used) passa por claimCoupon.
+func transitionCoupon(tx *gorm.DB, code string, to model.CouponStatus, at time.Time, from ...model.CouponStatus) error {
+	updates := map[string]interface{}{"status": to}
+	switch to {
+	case model.CouponExpired:
+		updates["expired_at"] = at
+	case model.CouponCancelled:
+		updates["cancelled_at"] = at
+	case model.CouponRefunded:
+		updates["refunded_at"] = at
+	}
+	res := tx.Model(&model.Coupon{}).Where("code = ? AND status IN ?", code, from).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+	var coupon model.Coupon
+	if err := tx.Select("status").Where("code = ?", code).First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCouponNotFound
+		}
+		return err
+	}
+	return couponStateError(coupon.Status)
+}
+
+// CheckCouponUsable confere se o cupom ainda pode ser usado na empresa. Um cupom vencido
+// que o job ainda não processou é recusado, mas continua "issued": a transição fica com
+// ExpireDue, que também faz o reembolso quando a vantagem manda.
+func CheckCouponUsable(coupon *model.Coupon, now time.Time) error {
+	if coupon.Status != model.CouponIssued {
+		return couponStateError(coupon.Status)
+	}
+	if coupon.ExpiresAt != nil && !now.Before(*coupon.ExpiresAt) {
+		return ErrCouponExpired
+	}
+	return nil
+}
+
+// BackfillCouponStatus preenche o estado dos cupons criados antes do ciclo de vida, que
+// só tinham as flags redeemed e refunded_at. É idempotente.
+func BackfillCouponStatus(db *gorm.DB) error {
+	if err := db.Model(&model.Coupon{}).
+		Where("status = ? AND redeemed = ?", model.CouponIssued, true).
+		Update("status", model.CouponUsed).Error; err != nil {
+		return err
+	}
+	return db.Model(&model.Coupon{}).
+		Where("status = ? AND refunded_at IS NOT NULL", model.CouponIssued).
+		Update("status", model.CouponRefunded).Error
+}
+
+// redeemOf carrega a transação de resgate que emitiu o cupom, já validando que ela
+// ainda não foi estornada.
+func redeemOf(tx *gorm.DB, code string, original *model.Transaction) error {
+	var found model.Transaction
+	if err := tx.Select("id").Where("code = ? AND type = ?", code, model.RedeemCoins).First(&found).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTransactionNotFound
+		}
+		return err
+	}
+	return loadReversible(tx, found.ID, original)
+}
+
+type CouponLifecycleService struct {
+	db              *gorm.DB
+	notificationSvc *NotificationService
+}
+
+func NewCouponLifecycleService(db *gorm.DB, notificationSvc *NotificationService) *CouponLifecycleService {
+	return &CouponLifecycleService{db: db, notificationSvc: notificationSvc}
+}
+
+// Cancel desiste de um cupom ainda não usado: as moedas voltam ao aluno e a unidade
+// volta ao estoque.
+func (s *CouponLifecycleService) Cancel(studentID, couponID uint) (*model.Coupon, error) {
+	var coupon model.Coupon
+	var original model.Transaction
+	replenished := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND student_id = ?", couponID, studentID).First(&coupon).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCouponNotFound
+			}
+			return err
+		}
+		now := time.Now()
+		if err := transitionCoupon(tx, coupon.Code, model.CouponCancelled, now, model.CouponIssued); err != nil {
+			return err
+		}
+		if err := redeemOf(tx, coupon.Code, &original); err != nil {
+			return err
+		}
+		var err error
+		_, replenished, err = refundRedeem(tx, &original, "cupom cancelado pelo aluno")
+		if err != nil {
+			return err
+		}
+		return tx.First(&coupon, coupon.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replenished {
+		go notifyWaitlist(s.db, s.notificationSvc, coupon.RewardID)
+	}
+	s.notify(original.ToUserID, "Cupom Cancelado",
+		fmt.Sprintf("O aluno cancelou o cupom %s; %d moedas foram devolvidas", coupon.Code, original.Amount))
+	return &coupon, nil
+}
+
+type CouponExpiryResult struct {
+	Expired  int `json:"expired"`
+	Refunded int `json:"refunded"`
+}
+
+// ExpireDue marca como expirados os cupons vencidos e, quando a vantagem manda
+// reembolsar, devolve as moedas. Cada cupom é processado na própria transação.
+func (s *CouponLifecycleService) ExpireDue(now time.Time) (*CouponExpiryResult, error) {
+	var coupons []model.Coupon
+	if err := s.db.Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", model.CouponIssued, now).
+		Find(&coupons).Error; err != nil {
+		return nil, err
+	}
+	result := &CouponExpiryResult{}
+	for _, coupon := range coupons {
+		refunded := false
+		var original model.Transaction
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := transitionCoupon(tx, coupon.Code, model.CouponExpired, now, model.CouponIssued); err != nil {
+				return err
+			}
+			var reward model.Reward
+			if err := tx.Select("id", "refund_on_expiry").First(&reward, coupon.RewardID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			if !reward.RefundOnExpiry {
+				return nil
+			}
+			if err := redeemOf(tx, coupon.Code, &original); err != nil {
+				return err
+			}
+			if _, _, err := refundRedeem(tx, &original, "cupom vencido"); err != nil {
+				return err
+			}
+			refunded = true
+			return tx.Model(&model.Coupon{}).Where("id = ?", coupon.ID).Update("refunded_at", now).Error
+		})
+		if err != nil {
+			var appErr *apperror.Error
+			if errors.As(err, &appErr) {
+				// Outro processo mudou o cupom ou o resgate já foi estornado
+				continue
+			}
+			return result, err
+		}
+		result.Expired++
+		message := fmt.Sprintf("O cupom %s venceu sem ser utilizado", coupon.Code)
+		if refunded {
+			result.Refunded++
+			message = fmt.Sprintf("O cupom %s venceu sem ser utilizado; %d moedas foram devolvidas", coupon.Code, original.Amount)
+		}
+		s.notify(&coupon.StudentID, "Cupom Vencido", message)
+	}
+	return result, nil
+}
+
+func (s *CouponLifecycleService) notify(userID *uint, title, message string) {
+	if s.notificationSvc == nil || userID == nil {
+		return
+	}
+	_ = s.notificationSvc.CreateNotification(*userID, model.NotificationTypeRefund, title, message)
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/coupontoken"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const signingKeyPurpose = "coupon-signing-key"
+
+// Tolerância para o relógio do PDV ao sincronizar cupons usados offline.
+const syncClockSkew = 5 * time.Minute
+
+var (
+	ErrCompanyNotFound    = apperror.New("company_not_found", http.StatusNotFound)
+	ErrInvalidCouponToken = apperror.New("invalid_coupon_token", http.StatusBadRequest)
+)
+
+// activeSigningKey devolve a chave com que a empresa assina cupons novos, criando a
+// primeira quando ainda não houver.
+func activeSigningKey(tx *gorm.DB, companyID uint) (*model.CompanySigningKey, error) {
+	var keys []model.CompanySigningKey
+	if err := tx.Where("company_id = ? AND retired_at IS NULL", companyID).
+		Order("id DESC").Limit(1).Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		return &keys[0], nil
+	}
+	return createSigningKey(tx, companyID)
+}
+
+// CheckSigningKeySecret confere na inicialização que o COUPON_KEY_SECRET abre as chaves
+// de assinatura já gravadas; com o segredo errado, todo resgate falharia ao assinar.
+func CheckSigningKeySecret(db *gorm.DB) error {
+	var keys []model.CompanySigningKey
+	if err := db.Where("retired_at IS NULL").Limit(1).Find(&keys).Error; err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if _, err := openSecret(signingKeyPurpose, keys[0].PrivateKeyEncrypted); err != nil {
+		return fmt.Errorf("COUPON_KEY_SECRET não abre a chave %s: %w", keys[0].KeyID, err)
+	}
+	return nil
+}
+
+func createSigningKey(tx *gorm.DB, companyID uint) (*model.CompanySigningKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealSecret(signingKeyPurpose, string(private.Seed()))
+	if err != nil {
+		return nil, err
+	}
+	kid := make([]byte, 8)
+	if _, err := rand.Read(kid); err != nil {
+		return nil, err
+	}
+	key := &model.CompanySigningKey{
+		CompanyID:           companyID,
+		KeyID:               hex.EncodeToString(kid),
+		PublicKey:           base64.StdEncoding.EncodeToString(public),
+		PrivateKeyEncrypted: sealed,
+	}
+	return key, tx.Create(key).Error
+}
+
+// SignCoupon grava no cupom o token assinado pela empresa dona da vantagem. Roda na
+// transação do resgate, depois que o cupom já tem ID.
+func SignCoupon(tx *gorm.DB, coupon *model.Coupon, companyID uint) error {
+	key, err := activeSigningKey(tx, companyID)
+	if err != nil {
+		return err
+	}
+	seed, err := openSecret(signingKeyPurpose, key.PrivateKeyEncrypted)
+	if err != nil {
+		return err
+	}
+	claims := coupontoken.Claims{
+		CouponID:  coupon.ID,
+		RewardID:  coupon.RewardID,
+		StudentID: coupon.StudentID,
+		CompanyID: companyID,
+		KeyID:     key.KeyID,
+		IssuedAt:  coupon.CreatedAt.Unix(),
+	}
+	if coupon.ExpiresAt != nil {
+		claims.ExpiresAt = coupon.ExpiresAt.Unix()
+	}
+	token, err := coupontoken.Sign(ed25519.NewKeyFromSeed([]byte(seed)), claims)
+	if err != nil {
+		return err
+	}
+	coupon.Token = token
+	return tx.Model(coupon).Update("token", token).Error
+}
+
+func companyKeySet(tx *gorm.DB, companyID uint) (coupontoken.KeySet, error) {
+	var keys []model.CompanySigningKey
+	if err := tx.Where("company_id = ?", companyID).Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	set := make(coupontoken.KeySet, len(keys))
+	for _, key := range keys {
+		public, err := coupontoken.ParsePublicKey(key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		set[key.KeyID] = public
+	}
+	return set, nil
+}
+
+type CouponSigningService struct {
+	db *gorm.DB
+}
+
+func NewCouponSigningService(db *gorm.DB) *CouponSigningService {
+	return &CouponSigningService{db: db}
+}
+
+// PublicKeys lista as chaves públicas da empresa, inclusive as aposentadas, que ainda
+// validam cupons antigos. Só lê: a primeira chave nasce no primeiro resgate (SignCoupon),
+// então empresa sem resgates devolve lista vazia.
+func (s *CouponSigningService) PublicKeys(companyID uint) ([]dto.CompanyKeyDTO, error) {
+	var company model.User
+	if err := s.db.Select("id").Where("id = ? AND role = ?", companyID, model.CompanyRole).
+		First(&company).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+	var keys []model.CompanySigningKey
+	if err := s.db.Where("company_id = ?", companyID).Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	result := make([]dto.CompanyKeyDTO, len(keys))
+	for i, key := range keys {
+		result[i] = dto.CompanyKeyDTO{
+			KeyID:     key.KeyID,
+			Algorithm: "Ed25519",
+			PublicKey: key.PublicKey,
+			CreatedAt: key.CreatedAt,
+			RetiredAt: key.RetiredAt,
+		}
+	}
+	return result, nil
+}
+
+// RotateKey aposenta a chave atual e passa a assinar com uma nova.
+func (s *CouponSigningService) RotateKey(companyID uint) (*dto.CompanyKeyDTO, error) {
+	var key *model.CompanySigningKey
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.CompanySigningKey{}).
+			Where("company_id = ? AND retired_at IS NULL", companyID).
+			Update("retired_at", time.Now()).Error; err != nil {
+			return err
+		}
+		var err error
+		if key, err = createSigningKey(tx, companyID); err != nil {
+			return err
+		}
+		return recordAudit(tx, companyID, "coupon_key.rotate", "user", &companyID, map[string]interface{}{"kid": key.KeyID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CompanyKeyDTO{KeyID: key.KeyID, Algorithm: "Ed25519", PublicKey: key.PublicKey, CreatedAt: key.CreatedAt}, nil
+}
+
+// verifyToken confere o token contra as chaves da empresa no instante at.
+func verifyToken(tx *gorm.DB, companyID uint, token string, at time.Time) (*model.Coupon, error) {
+	keys, err := companyKeySet(tx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := coupontoken.Verify(token, keys, at)
+	if err != nil && !errors.Is(err, coupontoken.ErrExpired) {
+		return nil, ErrInvalidCouponToken
+	}
+	if claims.CompanyID != companyID {
+		return nil, ErrInvalidCouponToken
+	}
+	var coupon model.Coupon
+	if err := tx.First(&coupon, claims.CouponID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+	if coupon.Token != token {
+		return nil, ErrInvalidCouponToken
+	}
+	return &coupon, nil
+}
+
+// Sync baixa os cupons que o PDV aceitou offline. Cada cupom é tratado à parte e o
+// resultado diz se foi baixado, se já estava usado ou por que foi recusado. Um cupom
+// usado antes de vencer é aceito mesmo que o job já o tenha marcado como expirado,
+// desde que as moedas não tenham sido devolvidas. origin identifica quem sincronizou.
+func (s *CouponSigningService) Sync(origin model.CouponValidation, items []dto.CouponSyncItemDTO) ([]dto.CouponSyncResultDTO, error) {
+	now := time.Now()
+	results := make([]dto.CouponSyncResultDTO, len(items))
+	for i, item := range items {
+		usedAt := item.UsedAt
+		if usedAt.After(now.Add(syncClockSkew)) {
+			usedAt = now
+		}
+		var couponID uint
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			coupon, err := verifyToken(tx, origin.CompanyID, item.Token, usedAt)
+			if err != nil {
+				return err
+			}
+			couponID = coupon.ID
+			if err := ensureCouponBranch(tx, coupon, origin.CompanyID, origin.BranchID); err != nil {
+				return err
+			}
+			if err := claimCoupon(tx, coupon, usedAt, model.CouponIssued, model.CouponExpired); err != nil {
+				return err
+			}
+			record := origin
+			record.CouponID = coupon.ID
+			record.Channel = ValidationOffline
+			record.ValidatedAt = usedAt
+			return tx.Create(&record).Error
+		})
+		result := dto.CouponSyncResultDTO{CouponID: couponID, Status: "synced"}
+		if err != nil {
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) {
+				return nil, err
+			}
+			result.Status = "rejected"
+			result.Code = appErr.Code
+			if errors.Is(err, ErrCouponAlreadyUsed) {
+				result.Status = "already_used"
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ValidationOnline  = "online"
+	ValidationOffline = "offline"
+)
+
+// claimCoupon dá baixa no cupom em um único UPDATE condicional: só uma transação
+// concorrente encontra o cupom ainda não usado, as demais recebem o motivo da recusa.
+// O vencimento é conferido no instante at, que na sincronização é a hora do uso no PDV.
+func claimCoupon(tx *gorm.DB, coupon *model.Coupon, at time.Time, from ...model.CouponStatus) error {
+	res := tx.Model(&model.Coupon{}).
+		Where("id = ? AND redeemed = ? AND refunded_at IS NULL AND status IN ?", coupon.ID, false, from).
+		Where("expires_at IS NULL OR expires_at > ?", at).
+		Updates(map[string]interface{}{"status": model.CouponUsed, "redeemed": true, "used_at": at})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		coupon.Status = model.CouponUsed
+		coupon.Redeemed = true
+		coupon.UsedAt = &at
+		return nil
+	}
+	var current model.Coupon
+	if err := tx.Select("status").First(&current, coupon.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCouponNotFound
+		}
+		return err
+	}
+	for _, status := range from {
+		if current.Status == status {
+			// Estado aceito, então a recusa veio da validade ou de um reembolso
+			return ErrCouponExpired
+		}
+	}
+	return couponStateError(current.Status)
+}
+
+// ensureCouponOwner recusa cupons de vantagens de outra empresa.
+func ensureCouponOwner(tx *gorm.DB, coupon *model.Coupon, companyID uint) error {
+	var reward model.Reward
+	if err := tx.Select("id", "company_id").First(&reward, coupon.RewardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotRewardOwner
+		}
+		return err
+	}
+	if reward.CompanyID != companyID {
+		return ErrNotRewardOwner
+	}
+	return nil
+}
+
+type CouponValidationService struct {
+	db *gorm.DB
+}
+
+func NewCouponValidationService(db *gorm.DB) *CouponValidationService {
+	return &CouponValidationService{db: db}
+}
+
+// Find localiza o cupom pelo token assinado, hash ou código, sem dar baixa.
+func (s *CouponValidationService) Find(companyID uint, in dto.CouponValidateDTO) (*model.Coupon, error) {
+	if in.Token != "" {
+		// O token já traz a empresa; verifyToken recusa os de outra
+		return verifyToken(s.db, companyID, in.Token, time.Now())
+	}
+	query := s.db.Where("code = ?", in.Code)
+	if in.Hash != "" {
+		query = s.db.Where("hash = ?", in.Hash)
+	}
+	var coupon model.Coupon
+	if err := query.First(&coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+	if err := ensureCouponOwner(s.db, &coupon, companyID); err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// Validate dá baixa no cupom apresentado no caixa. origin informa quem valida e onde
+// (CompanyID, ValidatedBy, BranchID, Location, IP, UserAgent) e vira o registro da validação.
+func (s *CouponValidationService) Validate(in dto.CouponValidateDTO, origin model.CouponValidation) (*model.Coupon, *model.CouponValidation, error) {
+	coupon, err := s.Find(origin.CompanyID, in)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if err := CheckCouponUsable(coupon, now); err != nil {
+		return nil, nil, err
+	}
+	if err := ensureCouponBranch(s.db, coupon, origin.CompanyID, origin.BranchID); err != nil {
+		return nil, nil, err
+	}
+	record := origin
+	record.CouponID = coupon.ID
+	record.Channel = ValidationOnline
+	record.ValidatedAt = now
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := claimCoupon(tx, coupon, now, model.CouponIssued); err != nil {
+			return err
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return coupon, &record, nil
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type validationFixture struct {
+	db      *gorm.DB
+	company model.User
+	other   model.User
+	student model.User
+	reward  model.Reward
+}
+
+func newValidationFixture(t *testing.T) *validationFixture {
+	t.Helper()
+	// Arquivo próprio por teste, aberto com o mesmo DSN de produção (config.SQLiteDSN)
+	dsn := config.SQLiteDSN("file:" + filepath.Join(t.TempDir(), "coupons.db"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Reward{}, &model.Coupon{}, &model.CouponValidation{}, &model.CompanySigningKey{}, &model.Branch{}, &model.RewardBranch{}); err != nil {
+		t.Fatal(err)
+	}
+	f := &validationFixture{
+		db:      db,
+		company: model.User{Name: "Loja", Email: "loja@test", Role: model.CompanyRole},
+		other:   model.User{Name: "Concorrente", Email: "outra@test", Role: model.CompanyRole},
+		student: model.User{Name: "Aluno", Email: "aluno@test", Role: model.StudentRole},
+	}
+	for _, u := range []*model.User{&f.company, &f.other, &f.student} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.reward = model.Reward{CompanyID: f.company.ID, Title: "Café", Cost: 10, Active: true}
+	if err := db.Create(&f.reward).Error; err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f *validationFixture) issue(t *testing.T, n int, expiresAt *time.Time) []model.Coupon {
+	t.Helper()
+	coupons := make([]model.Coupon, n)
+	for i := range coupons {
+		coupons[i] = model.Coupon{
+			RewardID:  f.reward.ID,
+			StudentID: f.student.ID,
+			Code:      fmt.Sprintf("CC-TEST%05d", i),
+			Hash:      fmt.Sprintf("hash-%05d", i),
+			Status:    model.CouponIssued,
+			ExpiresAt: expiresAt,
+		}
+		if err := f.db.Create(&coupons[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := SignCoupon(f.db, &coupons[i], f.company.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return coupons
+}
+
+func (f *validationFixture) origin() model.CouponValidation {
+	return model.CouponValidation{CompanyID: f.company.ID, ValidatedBy: f.company.ID, Location: "caixa 1"}
+}
+
+func (f *validationFixture) validations(t *testing.T, couponID uint) int64 {
+	t.Helper()
+	var n int64
+	if err := f.db.Model(&model.CouponValidation{}).Where("coupon_id = ?", couponID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// hammer roda fn em workers goroutines liberadas ao mesmo tempo.
+func hammer(workers int, fn func(worker int)) {
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			<-start
+			fn(w)
+		}(w)
+	}
+	close(start)
+	wg.Wait()
+}
+
+func TestValidateCouponConcurrentSingleWinner(t *testing.T) {
+	f := newValidationFixture(t)
+	svc := NewCouponValidationService(f.db)
+	coupon := f.issue(t, 1, nil)[0]
+
+	const workers = 32
+	var mu sync.Mutex
+	var successes int
+	var unexpected []error
+	hammer(workers, func(w int) {
+		// Cada caixa apresenta o cupom de um jeito
+		in := dto.CouponValidateDTO{Code: coupon.Code}
+		switch w % 3 {
+		case 1:
+			in = dto.CouponValidateDTO{Hash: coupon.Hash}
+		case 2:
+			in = dto.CouponValidateDTO{Token: coupon.Token}
+		}
+		origin := f.origin()
+		origin.Location = fmt.Sprintf("caixa %d", w)
+		_, _, err := svc.Validate(in, origin)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err == nil:
+			successes++
+		case errors.Is(err, ErrCouponAlreadyUsed):
+		default:
+			unexpected = append(unexpected, err)
+		}
+	})
+
+	if len(unexpected) > 0 {
+		t.Fatalf("erros inesperados: %v", unexpected)
+	}
+	if successes != 1 {
+		t.Fatalf("esperava 1 validação bem-sucedida, obteve %d", successes)
+	}
+	if n := f.validations(t, coupon.ID); n != 1 {
+		t.Fatalf("esperava 1 registro de validação, obteve %d", n)
+	}
+	var stored model.Coupon
+	f.db.First(&stored, coupon.ID)
+	if stored.Status != model.CouponUsed || !stored.Redeemed || stored.UsedAt == nil {
+		t.Fatalf("cupom não ficou usado: %+v", stored)
+	}
+}
+
+func TestValidateCouponConcurrentManyCoupons(t *testing.T) {
+	f := newValidationFixture(t)
+	svc := NewCouponValidationService(f.db)
+	coupons := f.issue(t, 20, nil)
+
+	const perCoupon = 8
+	wins := make([]int, len(coupons))
+	var mu sync.Mutex
+	hammer(len(coupons)*perCoupon, func(w int) {
+		i := w % len(coupons)
+		_, _, err := svc.Validate(dto.CouponValidateDTO{Code: coupons[i].Code}, f.origin())
+		if err != nil && !errors.Is(err, ErrCouponAlreadyUsed) {
+			t.Errorf("cupom %d: %v", i, err)
+			return
+		}
+		if err == nil {
+			mu.Lock()
+			wins[i]++
+			mu.Unlock()
+		}
+	})
+
+	for i, n := range wins {
+		if n != 1 {
+			t.Errorf("cupom %d validado %d vezes", i, n)
+		}
+		if v := f.validations(t, coupons[i].ID); v != 1 {
+			t.Errorf("cupom %d com %d registros de validação", i, v)
+		}
+	}
+}
+
+func TestValidateCouponConcurrentWithOfflineSync(t *testing.T) {
+	f := newValidationFixture(t)
+	svc := NewCouponValidationService(f.db)
+	signing := NewCouponSigningService(f.db)
+	coupons := f.issue(t, 10, nil)
+
+	wins := make([]int, len(coupons))
+	var mu sync.Mutex
+	win := func(i int) {
+		mu.Lock()
+		wins[i]++
+		mu.Unlock()
+	}
+	hammer(len(coupons)*6, func(w int) {
+		i := w % len(coupons)
+		if w%2 == 0 {
+			_, _, err := svc.Validate(dto.CouponValidateDTO{Token: coupons[i].Token}, f.origin())
+			if err == nil {
+				win(i)
+			} else if !errors.Is(err, ErrCouponAlreadyUsed) {
+				t.Errorf("online %d: %v", i, err)
+			}
+			return
+		}
+		results, err := signing.Sync(f.origin(), []dto.CouponSyncItemDTO{{Token: coupons[i].Token, UsedAt: time.Now()}})
+		if err != nil {
+			t.Errorf("sync %d: %v", i, err)
+			return
+		}
+		switch results[0].Status {
+		case "synced":
+			win(i)
+		case "already_used":
+		default:
+			t.Errorf("sync %d: %+v", i, results[0])
+		}
+	})
+
+	for i, n := range wins {
+		if n != 1 {
+			t.Errorf("cupom %d baixado %d vezes", i, n)
+		}
+	}
+	var offline int64
+	f.db.Model(&model.CouponValidation{}).Where("channel = ?", ValidationOffline).Count(&offline)
+	var online int64
+	f.db.Model(&model.CouponValidation{}).Where("channel = ?", ValidationOnline).Count(&online)
+	if offline+online != int64(len(coupons)) {
+		t.Fatalf("esperava %d registros, obteve %d online e %d offline", len(coupons), online, offline)
+	}
+}
+
+func TestValidateCouponOtherCompany(t *testing.T) {
+	f := newValidationFixture(t)
+	svc := NewCouponValidationService(f.db)
+	coupon := f.issue(t, 1, nil)[0]
+	origin := model.CouponValidation{CompanyID: f.other.ID, ValidatedBy: f.other.ID}
+
+	if _, _, err := svc.Validate(dto.CouponValidateDTO{Code: coupon.Code}, origin); !errors.Is(err, ErrNotRewardOwner) {
+		t.Fatalf("código: esperava ErrNotRewardOwner, obteve %v", err)
+	}
+	if _, _, err := svc.Validate(dto.CouponValidateDTO{Hash: coupon.Hash}, origin); !errors.Is(err, ErrNotRewardOwner) {
+		t.Fatalf("hash: esperava ErrNotRewardOwner, obteve %v", err)
+	}
+	if _, _, err := svc.Validate(dto.CouponValidateDTO{Token: coupon.Token}, origin); !errors.Is(err, ErrInvalidCouponToken) {
+		t.Fatalf("token: esperava ErrInvalidCouponToken, obteve %v", err)
+	}
+	var stored model.Coupon
+	f.db.First(&stored, coupon.ID)
+	if stored.Status != model.CouponIssued || stored.Redeemed {
+		t.Fatalf("cupom de outra empresa foi baixado: %+v", stored)
+	}
+	if n := f.validations(t, coupon.ID); n != 0 {
+		t.Fatalf("esperava nenhum registro, obteve %d", n)
+	}
+}
+
+func TestValidateCouponExpired(t *testing.T) {
+	f := newValidationFixture(t)
+	svc := NewCouponValidationService(f.db)
+	past := time.Now().Add(-time.Hour)
+	coupon := f.issue(t, 1, &past)[0]
+
+	if _, _, err := svc.Validate(dto.CouponValidateDTO{Code: coupon.Code}, f.origin()); !errors.Is(err, ErrCouponExpired) {
+		t.Fatalf("esperava ErrCouponExpired, obteve %v", err)
+	}
+	// A recusa não muda o estado: quem expira (e reembolsa) é o job
+	var stored model.Coupon
+	f.db.First(&stored, coupon.ID)
+	if stored.Status != model.CouponIssued {
+		t.Fatalf("esperava cupom ainda emitido, obteve %s", stored.Status)
+	}
+	result, err := NewCouponLifecycleService(f.db, nil).ExpireDue(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.db.First(&stored, coupon.ID)
+	if result.Expired != 1 || stored.Status != model.CouponExpired {
+		t.Fatalf("esperava cupom expirado pelo job, obteve %+v e %s", result, stored.Status)
+	}
+}
+
+func TestValidateCouponBranchRestriction(t *testing.T) {
+	f := newValidationFixture(t)
+	svc := NewCouponValidationService(f.db)
+	centro := model.Branch{CompanyID: f.company.ID, Name: "Centro"}
+	bairro := model.Branch{CompanyID: f.company.ID, Name: "Bairro"}
+	alheia := model.Branch{CompanyID: f.other.ID, Name: "Concorrente"}
+	for _, b := range []*model.Branch{&centro, &bairro, &alheia} {
+		if err := f.db.Create(b).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SetRewardBranches(f.db, f.company.ID, f.reward.ID, []uint{centro.ID}); err != nil {
+		t.Fatal(err)
+	}
+	coupon := f.issue(t, 1, nil)[0]
+	in := dto.CouponValidateDTO{Code: coupon.Code}
+	at := func(branchID *uint) model.CouponValidation {
+		origin := f.origin()
+		origin.BranchID = branchID
+		return origin
+	}
+
+	if _, _, err := svc.Validate(in, at(nil)); !errors.Is(err, ErrBranchRequired) {
+		t.Fatalf("sem filial: esperava ErrBranchRequired, obteve %v", err)
+	}
+	if _, _, err := svc.Validate(in, at(&bairro.ID)); !errors.Is(err, ErrRewardNotAtBranch) {
+		t.Fatalf("outra filial: esperava ErrRewardNotAtBranch, obteve %v", err)
+	}
+	if _, _, err := svc.Validate(in, at(&alheia.ID)); !errors.Is(err, ErrBranchNotFound) {
+		t.Fatalf("filial de outra empresa: esperava ErrBranchNotFound, obteve %v", err)
+	}
+	if n := f.validations(t, coupon.ID); n != 0 {
+		t.Fatalf("esperava nenhum registro, obteve %d", n)
+	}
+	_, validation, err := svc.Validate(in, at(&centro.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if validation.BranchID == nil || *validation.BranchID != centro.ID {
+		t.Fatalf("validação sem a filial: %+v", validation)
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// coinExpiryFor calcula a validade de moedas recebidas pelo aluno, usando a
+// configuração da instituição quando houver.
+func coinExpiryFor(tx *gorm.DB, student *model.User, from time.Time) time.Time {
+	days := config.CoinExpiryDays
+	if student.InstitutionID != nil {
+		var inst model.Institution
+		if err := tx.First(&inst, *student.InstitutionID).Error; err == nil && inst.CoinExpiryDays > 0 {
+			days = int(inst.CoinExpiryDays)
+		}
+	}
+	return from.AddDate(0, 0, days)
+}
+
+func findStudent(tx *gorm.DB, userID uint) (*model.User, error) {
+	var user model.User
+	if err := tx.Select("id", "role", "institution_id").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.Role != model.StudentRole {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+// trackLots mantém os lotes de moedas dos alunos em sincronia com uma transação
+// recém-registrada no livro-razão.
+func trackLots(tx *gorm.DB, t *model.Transaction) error {
+	if t.ToUserID != nil {
+		student, err := findStudent(tx, *t.ToUserID)
+		if err != nil {
+			return err
+		}
+		if student != nil {
+			if err := creditLots(tx, student, t); err != nil {
+				return err
+			}
+		}
+	}
+	// Expirações são registradas pelo próprio job, que já zera o lote
+	if t.FromUserID != nil && t.Type != model.Expire {
+		student, err := findStudent(tx, *t.FromUserID)
+		if err != nil {
+			return err
+		}
+		if student != nil {
+			return debitLots(tx, student.ID, t.Amount, t)
+		}
+	}
+	return nil
+}
+
+func creditLots(tx *gorm.DB, student *model.User, t *model.Transaction) error {
+	remaining := t.Amount
+	now := time.Now()
+
+	// Reembolsos devolvem as moedas aos lotes consumidos pelo resgate original
+	if t.Type == model.Refund && t.ReversalOfID != nil {
+		var consumptions []model.CoinLotConsumption
+		if err := tx.Where("transaction_id = ?", *t.ReversalOfID).Order("id").Find(&consumptions).Error; err != nil {
+			return err
+		}
+		for _, c := range consumptions {
+			if remaining == 0 {
+				break
+			}
+			amount := min(c.Amount, remaining)
+			res := tx.Model(&model.CoinLot{}).
+				Where("id = ? AND expired_at IS NULL AND expires_at > ?", c.LotID, now).
+				Update("remaining", gorm.Expr("remaining + ?", amount))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected > 0 {
+				remaining -= amount
+			}
+		}
+	}
+
+	if remaining == 0 {
+		return nil
+	}
+	lot := model.CoinLot{
+		UserID:        student.ID,
+		TransactionID: &t.ID,
+		Amount:        remaining,
+		Remaining:     remaining,
+		ExpiresAt:     coinExpiryFor(tx, student, now),
+	}
+	return tx.Create(&lot).Error
+}
+
+// debitLots consome lotes em ordem FIFO. Estornos consomem só o lote criado pelo
+// envio original. Com t nulo o consumo não é registrado (ajuste de sincronia).
+func debitLots(tx *gorm.DB, studentID, amount uint, t *model.Transaction) error {
+	reversal := t != nil && t.Type == model.Reversal && t.ReversalOfID != nil
+	query := tx.Where("user_id = ? AND remaining > 0 AND expired_at IS NULL", studentID)
+	if reversal {
+		query = query.Where("transaction_id = ?", *t.ReversalOfID)
+	}
+	var lots []model.CoinLot
+	if err := query.Order("created_at asc, id asc").Find(&lots).Error; err != nil {
+		return err
+	}
+
+	remaining := amount
+	for _, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+		take := min(lot.Remaining, remaining)
+		if err := tx.Model(&model.CoinLot{}).Where("id = ?", lot.ID).
+			Update("remaining", gorm.Expr("remaining - ?", take)).Error; err != nil {
+			return err
+		}
+		if t != nil {
+			if err := tx.Create(&model.CoinLotConsumption{LotID: lot.ID, TransactionID: t.ID, Amount: take}).Error; err != nil {
+				return err
+			}
+		}
+		remaining -= take
+	}
+	if reversal && remaining > 0 {
+		return ErrCoinsSpent
+	}
+	return nil
+}
+
+// activeLots lista os lotes ainda não expirados do aluno, do que vence primeiro ao último.
+func activeLots(db *gorm.DB, studentID uint) ([]dto.CoinExpirationDTO, error) {
+	expirations := []dto.CoinExpirationDTO{}
+	err := db.Model(&model.CoinLot{}).
+		Select("remaining as amount, expires_at").
+		Where("user_id = ? AND remaining > 0 AND expired_at IS NULL", studentID).
+		Order("expires_at asc").
+		Scan(&expirations).Error
+	return expirations, err
+}
+
+type CoinExpiryService struct {
+	db              *gorm.DB
+	notificationSvc *NotificationService
+}
+
+func NewCoinExpiryService(db *gorm.DB, notificationSvc *NotificationService) *CoinExpiryService {
+	return &CoinExpiryService{db: db, notificationSvc: notificationSvc}
+}
+
+func (s *CoinExpiryService) Run() error {
+	if err := s.SyncUntrackedBalances(); err != nil {
+		return err
+	}
+	if _, err := s.Sweep(); err != nil {
+		return err
+	}
+	return s.NotifyUpcoming()
+}
+
+// SyncUntrackedBalances cria lotes para saldos que não vieram de SendCoins (saldo de
+// abertura, ajustes) e descarta lotes que excedam o saldo atual do aluno.
+func (s *CoinExpiryService) SyncUntrackedBalances() error {
+	var rows []struct {
+		ID      uint
+		Balance uint
+		Tracked uint
+	}
+	if err := s.db.Model(&model.User{}).
+		Select("users.id, users.balance, COALESCE(SUM(coin_lots.remaining), 0) as tracked").
+		Joins("LEFT JOIN coin_lots ON coin_lots.user_id = users.id AND coin_lots.expired_at IS NULL").
+		Where("users.role = ?", model.StudentRole).
+		Group("users.id, users.balance").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if row.Balance == row.Tracked {
+			continue
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if row.Balance < row.Tracked {
+				return debitLots(tx, row.ID, row.Tracked-row.Balance, nil)
+			}
+			student, err := findStudent(tx, row.ID)
+			if err != nil || student == nil {
+				return err
+			}
+			amount := row.Balance - row.Tracked
+			return tx.Create(&model.CoinLot{
+				UserID:    row.ID,
+				Amount:    amount,
+				Remaining: amount,
+				ExpiresAt: coinExpiryFor(tx, student, time.Now()),
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sweep expira os lotes vencidos, registrando uma transação "expire" para cada um.
+func (s *CoinExpiryService) Sweep() (uint, error) {
+	now := time.Now()
+	var lots []model.CoinLot
+	if err := s.db.Where("expires_at <= ? AND remaining > 0 AND expired_at IS NULL", now).
+		Order("id").
+		Find(&lots).Error; err != nil {
+		return 0, err
+	}
+
+	var total uint
+	expiredByUser := make(map[uint]uint)
+	for _, lot := range lots {
+		var expired uint
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var current model.CoinLot
+			if err := tx.First(&current, lot.ID).Error; err != nil {
+				return err
+			}
+			if current.ExpiredAt != nil || current.Remaining == 0 {
+				return nil
+			}
+			var user model.User
+			if err := tx.Select("id", "balance").First(&user, current.UserID).Error; err != nil {
+				return err
+			}
+			expired = min(current.Remaining, user.Balance)
+			if expired > 0 {
+				t := model.Transaction{
+					FromUserID: &current.UserID,
+					ToUserID:   nil,
+					Amount:     expired,
+					Message:    fmt.Sprintf("Expiração de moedas recebidas em %s", current.CreatedAt.Format("02/01/2006")),
+					Type:       model.Expire,
+				}
+				if err := RecordTransfer(tx, &t); err != nil {
+					return err
+				}
+			}
+			return tx.Model(&current).Updates(map[string]interface{}{
+				"remaining":  0,
+				"expired_at": now,
+			}).Error
+		})
+		if err != nil {
+			log.Printf("Error expiring coin lot %d: %v", lot.ID, err)
+			continue
+		}
+		expiredByUser[lot.UserID] += expired
+		total += expired
+	}
+
+	for userID, amount := range expiredByUser {
+		if amount == 0 || s.notificationSvc == nil {
+			continue
+		}
+		if err := s.notificationSvc.CreateNotification(userID, model.NotificationTypeExpiration,
+			"Moedas Expiradas", fmt.Sprintf("%d moedas expiraram", amount)); err != nil {
+			log.Printf("Error creating notification for student %d: %v", userID, err)
+		}
+	}
+	return total, nil
+}
+
+// NotifyUpcoming avisa os alunos sobre moedas que expiram nos próximos
+// config.CoinExpiryNoticeDays dias. Cada lote é avisado uma única vez.
+func (s *CoinExpiryService) NotifyUpcoming() error {
+	if config.CoinExpiryNoticeDays == 0 {
+		return nil
+	}
+	now := time.Now()
+	deadline := now.AddDate(0, 0, config.CoinExpiryNoticeDays)
+	var lots []model.CoinLot
+	if err := s.db.Where("expires_at > ? AND expires_at <= ? AND remaining > 0 AND expired_at IS NULL AND notified_at IS NULL", now, deadline).
+		Order("expires_at asc").
+		Find(&lots).Error; err != nil {
+		return err
+	}
+
+	type upcoming struct {
+		amount   uint
+		earliest time.Time
+		lotIDs   []uint
+	}
+	byUser := make(map[uint]*upcoming)
+	for _, lot := range lots {
+		u, ok := byUser[lot.UserID]
+		if !ok {
+			u = &upcoming{earliest: lot.ExpiresAt}
+			byUser[lot.UserID] = u
+		}
+		u.amount += lot.Remaining
+		u.lotIDs = append(u.lotIDs, lot.ID)
+	}
+
+	for userID, u := range byUser {
+		if s.notificationSvc != nil {
+			if err := s.notificationSvc.CreateNotification(userID, model.NotificationTypeExpiration,
+				"Moedas Expirando",
+				fmt.Sprintf("%d moedas expiram a partir de %s. Aproveite para resgatar uma vantagem!", u.amount, u.earliest.Format("02/01/2006")),
+			); err != nil {
+				log.Printf("Error creating notification for student %d: %v", userID, err)
+				continue
+			}
+		}
+		if err := s.db.Model(&model.CoinLot{}).Where("id IN ?", u.lotIDs).Update("notified_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
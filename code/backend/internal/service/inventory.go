@@ -0,0 +1,219 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRewardSoldOut          = apperror.New("reward_sold_out", http.StatusConflict)
+	ErrRewardInStock          = apperror.New("reward_in_stock", http.StatusConflict)
+	ErrRedemptionLimitReached = apperror.New("redemption_limit_reached", http.StatusConflict)
+	ErrNotRewardOwner         = apperror.New("not_reward_owner", http.StatusForbidden)
+)
+
+// CheckRedemptionQuota aplica os limites por aluno da vantagem. Resgates reembolsados
+// ou cancelados não contam. Deve ser a primeira operação da transação do resgate: a
+// contagem só vale até o commit se nenhum outro resgate do aluno puder entrar no meio.
+func CheckRedemptionQuota(tx *gorm.DB, reward *model.Reward, studentID uint, now time.Time) error {
+	if reward.MaxPerStudent == 0 && reward.PeriodLimit == 0 {
+		return nil
+	}
+	// UPDATE sem efeito na linha do aluno: trava a linha até o commit (no SQLite, o
+	// banco inteiro), então dois resgates simultâneos do mesmo aluno contam um depois
+	// do outro em vez de os dois passarem pelo limite.
+	if err := tx.Model(&model.User{}).Where("id = ?", studentID).
+		UpdateColumn("balance", gorm.Expr("balance")).Error; err != nil {
+		return err
+	}
+	base := func() *gorm.DB {
+		return tx.Model(&model.Coupon{}).
+			Where("reward_id = ? AND student_id = ? AND refunded_at IS NULL AND status <> ?",
+				reward.ID, studentID, model.CouponCancelled)
+	}
+	if reward.MaxPerStudent > 0 {
+		var total int64
+		if err := base().Count(&total).Error; err != nil {
+			return err
+		}
+		if total >= int64(reward.MaxPerStudent) {
+			return ErrRedemptionLimitReached.With("limit", reward.MaxPerStudent)
+		}
+	}
+	if reward.PeriodLimit > 0 && reward.PeriodDays > 0 {
+		since := now.AddDate(0, 0, -int(reward.PeriodDays))
+		var inPeriod int64
+		if err := base().Where("created_at >= ?", since).Count(&inPeriod).Error; err != nil {
+			return err
+		}
+		if inPeriod >= int64(reward.PeriodLimit) {
+			return ErrRedemptionLimitReached.WithKey("redemption_limit_reached.period").
+				With("limit", reward.PeriodLimit).
+				With("periodDays", reward.PeriodDays)
+		}
+	}
+	return nil
+}
+
+// ReserveStock baixa uma unidade do estoque dentro da transação do resgate. O
+// decremento é condicional no próprio UPDATE, então dois resgates simultâneos não
+// vendem a mesma última unidade. Ao zerar, a vantagem sai do catálogo.
+func ReserveStock(tx *gorm.DB, reward *model.Reward) error {
+	if reward.Stock == nil {
+		return nil
+	}
+	res := tx.Model(&model.Reward{}).
+		Where("id = ? AND stock > 0", reward.ID).
+		Update("stock", gorm.Expr("stock - 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRewardSoldOut
+	}
+	return tx.Model(&model.Reward{}).
+		Where("id = ? AND stock = 0", reward.ID).
+		Updates(map[string]interface{}{"active": false, "sold_out_at": time.Now()}).Error
+}
+
+// releaseStock devolve uma unidade (reembolso de resgate). Retorna true quando a
+// vantagem estava esgotada e voltou ao catálogo, para avisar a lista de espera.
+func releaseStock(tx *gorm.DB, rewardID uint) (bool, error) {
+	var reward model.Reward
+	if err := tx.Select("id", "stock", "sold_out_at").First(&reward, rewardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if reward.Stock == nil {
+		return false, nil
+	}
+	if err := tx.Model(&model.Reward{}).Where("id = ?", rewardID).
+		Update("stock", gorm.Expr("stock + 1")).Error; err != nil {
+		return false, err
+	}
+	return reopenSoldOut(tx, &reward)
+}
+
+// reopenSoldOut reativa uma vantagem que havia sido desativada por falta de estoque.
+// Desativações feitas pela empresa (SoldOutAt nulo) são respeitadas.
+func reopenSoldOut(tx *gorm.DB, reward *model.Reward) (bool, error) {
+	if reward.SoldOutAt == nil {
+		return false, nil
+	}
+	err := tx.Model(&model.Reward{}).Where("id = ?", reward.ID).
+		Updates(map[string]interface{}{"active": true, "sold_out_at": nil}).Error
+	return err == nil, err
+}
+
+type InventoryService struct {
+	db              *gorm.DB
+	notificationSvc *NotificationService
+}
+
+func NewInventoryService(db *gorm.DB, notificationSvc *NotificationService) *InventoryService {
+	return &InventoryService{db: db, notificationSvc: notificationSvc}
+}
+
+// SetStock repõe (ou remove, com nil) o estoque de uma vantagem da empresa.
+func (s *InventoryService) SetStock(companyID, rewardID uint, stock *uint) (*model.Reward, error) {
+	var reward model.Reward
+	replenished := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&reward, rewardID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRewardNotFound
+			}
+			return err
+		}
+		if reward.CompanyID != companyID {
+			return ErrNotRewardOwner
+		}
+		if err := tx.Model(&reward).Update("stock", stock).Error; err != nil {
+			return err
+		}
+		reward.Stock = stock
+		if stock != nil && *stock == 0 {
+			if reward.SoldOutAt != nil {
+				return nil
+			}
+			now := time.Now()
+			reward.Active = false
+			reward.SoldOutAt = &now
+			return tx.Model(&reward).Updates(map[string]interface{}{"active": false, "sold_out_at": now}).Error
+		}
+		var err error
+		replenished, err = reopenSoldOut(tx, &reward)
+		if replenished {
+			reward.Active = true
+			reward.SoldOutAt = nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replenished {
+		go notifyWaitlist(s.db, s.notificationSvc, reward.ID)
+	}
+	return &reward, nil
+}
+
+// JoinWaitlist inscreve o aluno para ser avisado da reposição. Só faz sentido para
+// vantagens esgotadas; repetir a inscrição não gera duplicata.
+func (s *InventoryService) JoinWaitlist(studentID, rewardID uint) (*model.RewardWaitlist, error) {
+	var reward model.Reward
+	if err := s.db.First(&reward, rewardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRewardNotFound
+		}
+		return nil, err
+	}
+	if reward.SuspendedAt != nil {
+		return nil, ErrRewardNotFound
+	}
+	if err := EnsureRewardVisibleTo(s.db, reward.ID, studentID); err != nil {
+		return nil, err
+	}
+	if reward.SoldOutAt == nil {
+		return nil, ErrRewardInStock
+	}
+	entry := model.RewardWaitlist{RewardID: reward.ID, StudentID: studentID}
+	err := s.db.Where(entry).
+		Assign(map[string]interface{}{"notified_at": nil}).
+		FirstOrCreate(&entry).Error
+	return &entry, err
+}
+
+func (s *InventoryService) LeaveWaitlist(studentID, rewardID uint) error {
+	return s.db.Where("reward_id = ? AND student_id = ?", rewardID, studentID).
+		Delete(&model.RewardWaitlist{}).Error
+}
+
+// notifyWaitlist avisa quem aguardava a vantagem e marca as inscrições como atendidas.
+func notifyWaitlist(db *gorm.DB, notificationSvc *NotificationService, rewardID uint) {
+	if notificationSvc == nil {
+		return
+	}
+	var reward model.Reward
+	if err := db.Select("id", "title").First(&reward, rewardID).Error; err != nil {
+		return
+	}
+	var entries []model.RewardWaitlist
+	if err := db.Where("reward_id = ? AND notified_at IS NULL", rewardID).Find(&entries).Error; err != nil {
+		return
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		_ = notificationSvc.CreateNotification(entry.StudentID, model.NotificationTypeRestock,
+			"Vantagem Disponível", fmt.Sprintf("A vantagem %s voltou ao estoque", reward.Title))
+		db.Model(&entry).Update("notified_at", now)
+	}
+}
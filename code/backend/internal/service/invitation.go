@@ -0,0 +1,377 @@
+package service
+
+import (
+	"bufio"
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/mail"
+	"campuscash-backend/pkg/validator"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidInvitation = apperror.New("invalid_invitation", http.StatusBadRequest)
+	ErrInvitationExpired = apperror.New("invitation_expired", http.StatusGone)
+	ErrInvalidCSV        = apperror.New("invalid_csv", http.StatusBadRequest)
+	ErrCPFInUse          = apperror.New("cpf_in_use", http.StatusConflict)
+	ErrInvalidProfessor  = apperror.New("invalid_professor", http.StatusBadRequest)
+
+	// Erros por linha da importação, devolvidos no relatório e não como resposta
+	ErrMalformedRow = apperror.New("malformed_row", http.StatusBadRequest)
+	ErrDuplicateRow = apperror.New("duplicate_row", http.StatusBadRequest)
+)
+
+// Colunas aceitas no CSV de professores (inglês ou português)
+var professorCSVColumns = map[string]string{
+	"name":         "name",
+	"nome":         "name",
+	"email":        "email",
+	"e-mail":       "email",
+	"cpf":          "cpf",
+	"department":   "department",
+	"departamento": "department",
+}
+
+type InvitationService struct {
+	db *gorm.DB
+}
+
+func NewInvitationService(db *gorm.DB) *InvitationService {
+	return &InvitationService{db: db}
+}
+
+// issueInvitation gera um novo token para o usuário, invalidando o anterior.
+func issueInvitation(tx *gorm.DB, userID, actorID uint) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	inv := model.Invitation{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		InvitedBy: actorID,
+		ExpiresAt: time.Now().Add(time.Duration(config.InvitationTTLHours) * time.Hour),
+	}
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "invited_by", "expires_at", "updated_at"}),
+	}).Create(&inv).Error
+	return token, err
+}
+
+func sendInvitation(user model.User, token string) {
+	link := fmt.Sprintf("%s/ativar-conta?token=%s", strings.TrimRight(config.FrontendURL, "/"), token)
+	body := fmt.Sprintf("Olá, %s!\n\nVocê foi convidado(a) para o CampusCash como professor(a).\n"+
+		"Defina sua senha pelo link abaixo (válido por %d horas):\n\n%s\n", user.Name, config.InvitationTTLHours, link)
+	go func() {
+		if err := mail.SendMail(user.Email, "Ative sua conta CampusCash", body); err != nil {
+			log.Printf("Error sending invitation to %s: %v", user.Email, err)
+		}
+	}()
+}
+
+func (s *InvitationService) institution(id uint) (*model.Institution, error) {
+	var inst model.Institution
+	if err := s.db.First(&inst, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstitutionNotFound
+		}
+		return nil, err
+	}
+	return &inst, nil
+}
+
+func validateProfessorRow(input dto.ProfessorInviteDTO) error {
+	switch {
+	case strings.TrimSpace(input.Name) == "":
+		return ErrInvalidProfessor.With("field", "name")
+	case !validator.ValidateEmail(input.Email):
+		return ErrInvalidProfessor.With("field", "email")
+	case !validator.ValidateCPF(input.CPF):
+		return ErrInvalidProfessor.With("field", "cpf")
+	case strings.TrimSpace(input.Department) == "":
+		return ErrInvalidProfessor.With("field", "department")
+	}
+	return nil
+}
+
+// inviteProfessor cria a conta pendente (sem senha) e o convite em uma única transação.
+func (s *InvitationService) inviteProfessor(actorID uint, inst *model.Institution, input dto.ProfessorInviteDTO) (*model.User, error) {
+	if err := validateProfessorRow(input); err != nil {
+		return nil, err
+	}
+	cpf := validator.Digits(input.CPF)
+	department := strings.TrimSpace(input.Department)
+	user := model.User{
+		Name:          strings.TrimSpace(input.Name),
+		Email:         strings.ToLower(strings.TrimSpace(input.Email)),
+		Role:          model.ProfessorRole,
+		CPF:           &cpf,
+		Department:    &department,
+		InstitutionID: &inst.ID,
+	}
+
+	var token string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.User{}).Where("LOWER(email) = ?", strings.ToLower(user.Email)).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmailInUse
+		}
+		if err := tx.Model(&model.User{}).Where("cpf = ?", cpf).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCPFInUse
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if _, err := AccountForUser(tx, user.ID); err != nil {
+			return err
+		}
+		var err error
+		if token, err = issueInvitation(tx, user.ID, actorID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "professor.invite", "user", &user.ID,
+			map[string]interface{}{"email": user.Email, "institution": inst.Name})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sendInvitation(user, token)
+	return &user, nil
+}
+
+func (s *InvitationService) InviteProfessor(actorID uint, input dto.ProfessorInviteDTO) (*dto.AdminUserDTO, error) {
+	inst, err := s.institution(input.InstitutionID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.inviteProfessor(actorID, inst, input)
+	if err != nil {
+		return nil, err
+	}
+	result := toAdminUserDTO(*user)
+	return &result, nil
+}
+
+// ImportProfessors lê um CSV com as colunas name, email, cpf e department e convida
+// cada professor. Cada linha é processada isoladamente: falhas não desfazem as demais.
+func (s *InvitationService) ImportProfessors(actorID, institutionID uint, file io.Reader) (*dto.ProfessorImportReportDTO, error) {
+	inst, err := s.institution(institutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Planilhas exportadas em pt-BR costumam usar ";" como separador
+	buffered := bufio.NewReader(file)
+	firstLine, _ := buffered.Peek(4096)
+	if i := strings.IndexByte(string(firstLine), '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	reader := csv.NewReader(buffered)
+	if strings.Count(string(firstLine), ";") > strings.Count(string(firstLine), ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidCSV.WithKey("invalid_csv.missing_header")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := professorCSVColumns[name]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range []string{"name", "email", "cpf", "department"} {
+		if _, ok := columns[field]; !ok {
+			return nil, ErrInvalidCSV.WithKey("invalid_csv.missing_column").With("column", field)
+		}
+	}
+
+	report := &dto.ProfessorImportReportDTO{Rows: []dto.ProfessorImportRowDTO{}}
+	seenEmails := make(map[string]int)
+	seenCPFs := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Rows = append(report.Rows, dto.ProfessorImportRowDTO{Line: parseErr.Line, Status: "error", Code: ErrMalformedRow.Code})
+			report.Failed++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		input := dto.ProfessorInviteDTO{
+			Name:          field("name"),
+			Email:         field("email"),
+			CPF:           validator.Digits(field("cpf")),
+			Department:    field("department"),
+			InstitutionID: inst.ID,
+		}
+		row := dto.ProfessorImportRowDTO{Line: line, Email: input.Email}
+
+		email := strings.ToLower(input.Email)
+		if prev, ok := seenEmails[email]; ok && email != "" {
+			err = ErrDuplicateRow.With("field", "email").With("line", prev)
+		} else if prev, ok := seenCPFs[input.CPF]; ok && input.CPF != "" {
+			err = ErrDuplicateRow.With("field", "cpf").With("line", prev)
+		} else {
+			var user *model.User
+			if user, err = s.inviteProfessor(actorID, inst, input); err == nil {
+				row.UserID = &user.ID
+				seenEmails[email] = line
+				seenCPFs[input.CPF] = line
+			}
+		}
+
+		if err != nil {
+			row.Status = "error"
+			row.Code, row.Details = importRowError(err)
+			report.Failed++
+		} else {
+			row.Status = "created"
+			report.Created++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	report.Total = len(report.Rows)
+	return report, nil
+}
+
+// importRowError reduz o erro de uma linha ao código e detalhes do relatório; falhas
+// inesperadas (banco) ficam só no log.
+func importRowError(err error) (string, map[string]interface{}) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return appErr.Code, appErr.Details
+	}
+	log.Printf("Error importing professor row: %v", err)
+	return apperror.ErrInternal.Code, nil
+}
+
+// ResendInvitation gera um novo link para um professor que ainda não ativou a conta.
+func (s *InvitationService) ResendInvitation(actorID, userID uint) error {
+	var user model.User
+	var token string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("AvatarData").First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		var inv model.Invitation
+		if err := tx.Where("user_id = ? AND accepted_at IS NULL", userID).First(&inv).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidInvitation
+			}
+			return err
+		}
+		var err error
+		if token, err = issueInvitation(tx, userID, actorID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "professor.resend_invitation", "user", &userID, nil)
+	})
+	if err != nil {
+		return err
+	}
+	sendInvitation(user, token)
+	return nil
+}
+
+func (s *InvitationService) pendingInvitation(tx *gorm.DB, token string) (*model.Invitation, error) {
+	var inv model.Invitation
+	if err := tx.Where("token_hash = ? AND accepted_at IS NULL", hashToken(token)).First(&inv).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+	if time.Now().After(inv.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	return &inv, nil
+}
+
+// Lookup retorna os dados do convite para a tela de ativação.
+func (s *InvitationService) Lookup(token string) (*dto.InvitationInfoDTO, error) {
+	inv, err := s.pendingInvitation(s.db, token)
+	if err != nil {
+		return nil, err
+	}
+	var user model.User
+	if err := s.db.Select("name", "email", "institution_id").First(&user, inv.UserID).Error; err != nil {
+		return nil, err
+	}
+	info := &dto.InvitationInfoDTO{Name: user.Name, Email: user.Email}
+	if name := institutionName(s.db, user.InstitutionID); name != "" {
+		info.Institution = &name
+	}
+	return info, nil
+}
+
+// Activate define a senha do professor e consome o convite.
+func (s *InvitationService) Activate(token, password string) error {
+	var errs validator.Errors
+	if errs.Password("password", password); len(errs) > 0 {
+		return errs
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		inv, err := s.pendingInvitation(tx, token)
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&model.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL", inv.ID).
+			Update("accepted_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidInvitation
+		}
+		// O link do convite chegou por email, então o endereço está confirmado
+		return tx.Model(&model.User{}).Where("id = ?", inv.UserID).Updates(map[string]interface{}{
+			"password_hash":     string(hash),
+			"email_verified_at": time.Now(),
+		}).Error
+	})
+}
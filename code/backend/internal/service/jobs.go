@@ -0,0 +1,69 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RegisterJobs registra no scheduler os jobs periódicos do sistema.
+func RegisterJobs(scheduler *Scheduler, db *gorm.DB, notificationSvc *NotificationService, loginGuard *LoginGuard) error {
+	budgetSvc := NewBudgetService(db, notificationSvc)
+	if err := scheduler.Register("semester-allotment", config.AllotmentJobSchedule, func() (interface{}, error) {
+		return budgetSvc.CreditCurrentPeriod()
+	}); err != nil {
+		return err
+	}
+
+	reconciliationSvc := NewReconciliationService(db)
+	if err := scheduler.Register("reconciliation", config.ReconcileJobSchedule, func() (interface{}, error) {
+		report, err := reconciliationSvc.Run(config.ReconcileAutoRepair)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Reconciliation checked %d users, found %d discrepancies (repair: %t)",
+			report.UsersChecked, len(report.Discrepancies), report.RepairApplied)
+		return report, nil
+	}); err != nil {
+		return err
+	}
+
+	sessionSvc := NewSessionService(db)
+	mfaSvc := NewMFAService(db)
+	if err := scheduler.Register("session-cleanup", "@daily", func() (interface{}, error) {
+		removed, err := sessionSvc.Cleanup()
+		if err != nil {
+			return nil, err
+		}
+		challenges, err := mfaSvc.Cleanup()
+		if err != nil {
+			return nil, err
+		}
+		attempts, err := loginGuard.Cleanup()
+		return map[string]int64{"removed": removed, "mfaChallengesRemoved": challenges, "loginAttemptsRemoved": attempts}, err
+	}); err != nil {
+		return err
+	}
+
+	launchSvc := NewRewardLaunchService(db, notificationSvc)
+	if err := scheduler.Register("reward-launch", config.RewardLaunchJobSchedule, func() (interface{}, error) {
+		notified, err := launchSvc.NotifyLaunched(time.Now())
+		return map[string]int{"notified": notified}, err
+	}); err != nil {
+		return err
+	}
+
+	couponLifecycleSvc := NewCouponLifecycleService(db, notificationSvc)
+	if err := scheduler.Register("coupon-expiration", config.CouponExpiryJobSchedule, func() (interface{}, error) {
+		return couponLifecycleSvc.ExpireDue(time.Now())
+	}); err != nil {
+		return err
+	}
+
+	coinExpirySvc := NewCoinExpiryService(db, notificationSvc)
+	return scheduler.Register("coin-expiration", config.CoinExpiryJobSchedule, func() (interface{}, error) {
+		return nil, coinExpirySvc.Run()
+	})
+}
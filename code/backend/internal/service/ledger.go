@@ -0,0 +1,285 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInsufficientBalance = apperror.New("insufficient_balance", http.StatusBadRequest)
+	ErrInvalidAmount       = apperror.New("invalid_amount", http.StatusBadRequest)
+	ErrUnbalancedEntry     = errors.New("lançamento contábil não fecha em zero")
+)
+
+// mintAccount retorna a conta de emissão do sistema, de onde saem os créditos
+// semestrais. Seu saldo é negativo e igual ao total de moedas em circulação.
+func mintAccount(tx *gorm.DB) (*model.LedgerAccount, error) {
+	var acc model.LedgerAccount
+	err := tx.Where(model.LedgerAccount{Kind: model.MintAccount}).
+		Attrs(model.LedgerAccount{Name: "Emissão CampusCash"}).
+		FirstOrCreate(&acc).Error
+	if err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+// AccountForUser retorna (criando se necessário) a conta do usuário no livro-razão.
+// Um saldo pré-existente em User.Balance é registrado como lançamento de abertura.
+func AccountForUser(tx *gorm.DB, userID uint) (*model.LedgerAccount, error) {
+	var acc model.LedgerAccount
+	err := tx.Where("user_id = ?", userID).First(&acc).Error
+	if err == nil {
+		return &acc, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var user model.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	kind := model.UserAccount
+	if user.Role == model.CompanyRole {
+		kind = model.CompanyAccount
+	}
+	acc = model.LedgerAccount{Kind: kind, UserID: &user.ID, Name: user.Name}
+	if err := tx.Create(&acc).Error; err != nil {
+		return nil, err
+	}
+
+	if user.Balance > 0 {
+		mint, err := mintAccount(tx)
+		if err != nil {
+			return nil, err
+		}
+		entry := &model.JournalEntry{Type: model.OpeningBalance, Memo: "Saldo de abertura"}
+		if err := PostEntry(tx, entry, []model.Posting{
+			{AccountID: mint.ID, Amount: -int64(user.Balance)},
+			{AccountID: acc.ID, Amount: int64(user.Balance)},
+		}); err != nil {
+			return nil, err
+		}
+		if err := tx.First(&acc, acc.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &acc, nil
+}
+
+func accountOrMint(tx *gorm.DB, userID *uint) (*model.LedgerAccount, error) {
+	if userID == nil {
+		return mintAccount(tx)
+	}
+	return AccountForUser(tx, *userID)
+}
+
+// PostEntry grava um lançamento e seus postings e atualiza as projeções de saldo
+// (LedgerAccount.Balance e User.Balance). Deve ser chamado dentro de uma transação.
+func PostEntry(tx *gorm.DB, entry *model.JournalEntry, postings []model.Posting) error {
+	if len(postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+	var sum int64
+	for _, p := range postings {
+		if p.Amount == 0 {
+			return fmt.Errorf("posting com valor zero na conta %d", p.AccountID)
+		}
+		sum += p.Amount
+	}
+	if sum != 0 {
+		return ErrUnbalancedEntry
+	}
+
+	entry.Postings = postings
+	if err := tx.Create(entry).Error; err != nil {
+		return err
+	}
+	for _, p := range entry.Postings {
+		if err := applyPosting(tx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyPosting(tx *gorm.DB, p model.Posting) error {
+	// Atualização condicional: apenas a conta de emissão pode ficar negativa
+	res := tx.Model(&model.LedgerAccount{}).
+		Where("id = ? AND (kind = ? OR balance + ? >= 0)", p.AccountID, model.MintAccount, p.Amount).
+		Update("balance", gorm.Expr("balance + ?", p.Amount))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInsufficientBalance
+	}
+
+	var acc model.LedgerAccount
+	if err := tx.First(&acc, p.AccountID).Error; err != nil {
+		return err
+	}
+	if acc.UserID != nil {
+		return tx.Model(&model.User{}).
+			Where("id = ?", *acc.UserID).
+			Update("balance", uint(acc.Balance)).Error
+	}
+	return nil
+}
+
+// RecordTransfer persiste a transação e o lançamento contábil correspondente.
+// FromUserID/ToUserID nulos representam a conta de emissão do sistema.
+func RecordTransfer(tx *gorm.DB, t *model.Transaction) error {
+	if t.Amount == 0 {
+		return ErrInvalidAmount
+	}
+	from, err := accountOrMint(tx, t.FromUserID)
+	if err != nil {
+		return err
+	}
+	to, err := accountOrMint(tx, t.ToUserID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(t).Error; err != nil {
+		return err
+	}
+	entry := &model.JournalEntry{Type: t.Type, TransactionID: &t.ID, Memo: t.Message}
+	if err := PostEntry(tx, entry, []model.Posting{
+		{AccountID: from.ID, Amount: -int64(t.Amount)},
+		{AccountID: to.ID, Amount: int64(t.Amount)},
+	}); err != nil {
+		return err
+	}
+	return trackLots(tx, t)
+}
+
+type LedgerService struct {
+	db *gorm.DB
+}
+
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{db: db}
+}
+
+// Bootstrap garante que todo usuário possua uma conta no livro-razão.
+func (s *LedgerService) Bootstrap() error {
+	var userIDs []uint
+	if err := s.db.Model(&model.User{}).
+		Where("id NOT IN (?)", s.db.Model(&model.LedgerAccount{}).Select("user_id").Where("user_id IS NOT NULL")).
+		Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			_, err := AccountForUser(tx, id)
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ComputeBalance reconstrói o saldo de uma conta somando seus postings.
+func (s *LedgerService) ComputeBalance(accountID uint) (int64, error) {
+	var result struct {
+		Total int64
+	}
+	err := s.db.Model(&model.Posting{}).
+		Select("COALESCE(SUM(amount), 0) as total").
+		Where("account_id = ?", accountID).
+		Scan(&result).Error
+	return result.Total, err
+}
+
+// Verify compara as projeções em cache com o saldo derivado dos postings.
+func (s *LedgerService) Verify() ([]dto.LedgerDiscrepancyDTO, error) {
+	var accounts []model.LedgerAccount
+	if err := s.db.Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	discrepancies := []dto.LedgerDiscrepancyDTO{}
+	for _, acc := range accounts {
+		computed, err := s.ComputeBalance(acc.ID)
+		if err != nil {
+			return nil, err
+		}
+		var userBalance *uint
+		if acc.UserID != nil {
+			var user model.User
+			if err := s.db.Select("balance").First(&user, *acc.UserID).Error; err == nil {
+				userBalance = &user.Balance
+			}
+		}
+		if computed != acc.Balance || (userBalance != nil && int64(*userBalance) != computed) {
+			discrepancies = append(discrepancies, dto.LedgerDiscrepancyDTO{
+				AccountID:     acc.ID,
+				UserID:        acc.UserID,
+				Computed:      computed,
+				CachedAccount: acc.Balance,
+				CachedUser:    userBalance,
+			})
+		}
+	}
+	return discrepancies, nil
+}
+
+// RebuildProjections recalcula todas as projeções de saldo a partir do diário.
+func (s *LedgerService) RebuildProjections() error {
+	var accounts []model.LedgerAccount
+	if err := s.db.Find(&accounts).Error; err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, acc := range accounts {
+			var result struct {
+				Total int64
+			}
+			if err := tx.Model(&model.Posting{}).
+				Select("COALESCE(SUM(amount), 0) as total").
+				Where("account_id = ?", acc.ID).
+				Scan(&result).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.LedgerAccount{}).Where("id = ?", acc.ID).
+				Update("balance", result.Total).Error; err != nil {
+				return err
+			}
+			if acc.UserID != nil && result.Total >= 0 {
+				if err := tx.Model(&model.User{}).Where("id = ?", *acc.UserID).
+					Update("balance", uint(result.Total)).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Statement lista os postings da conta do usuário, do mais recente ao mais antigo.
+func (s *LedgerService) Statement(userID uint) ([]dto.LedgerPostingDTO, error) {
+	statement := []dto.LedgerPostingDTO{}
+	var acc model.LedgerAccount
+	if err := s.db.Where("user_id = ?", userID).First(&acc).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return statement, nil
+		}
+		return nil, err
+	}
+	err := s.db.Table("postings").
+		Select("postings.entry_id, postings.amount, journal_entries.type, journal_entries.transaction_id, journal_entries.memo, postings.created_at").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.entry_id").
+		Where("postings.account_id = ?", acc.ID).
+		Order("postings.id desc").
+		Scan(&statement).Error
+	return statement, err
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/internal/repository"
+	"campuscash-backend/pkg/mail"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tentativas recusadas antes de conferir a senha; detalhe "retryAfter" em segundos.
+// ErrLoginLocked é o bloqueio temporário da conta, ErrLoginThrottled a espera entre tentativas.
+var (
+	ErrLoginThrottled = apperror.New("login_throttled", http.StatusTooManyRequests)
+	ErrLoginLocked    = apperror.New("login_locked", http.StatusTooManyRequests)
+)
+
+// NewLoginAttemptStore escolhe o backend conforme LOGIN_ATTEMPT_STORE.
+func NewLoginAttemptStore(db *gorm.DB) repository.LoginAttemptStore {
+	if config.LoginAttemptStore == "memory" {
+		return repository.NewMemoryLoginAttemptStore()
+	}
+	return repository.NewLoginAttemptStore(db)
+}
+
+// LoginGuard limita tentativas de login por conta e por IP: cada falha da conta dobra
+// a espera até a próxima tentativa e, ao atingir o limite, a chave fica bloqueada.
+type LoginGuard struct {
+	store           repository.LoginAttemptStore
+	db              *gorm.DB
+	notificationSvc *NotificationService
+}
+
+func NewLoginGuard(store repository.LoginAttemptStore, db *gorm.DB, notificationSvc *NotificationService) *LoginGuard {
+	return &LoginGuard{store: store, db: db, notificationSvc: notificationSvc}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func (g *LoginGuard) window() time.Duration {
+	return time.Duration(config.LoginFailureWindowMinutes) * time.Minute
+}
+
+// blockedUntil calcula até quando a chave fica impedida a partir das falhas registradas.
+// Sem backoff, a chave só é barrada ao atingir o limite.
+func (g *LoginGuard) blockedUntil(attempt *model.LoginAttempt, threshold int, backoff bool) (time.Time, bool) {
+	if attempt == nil || attempt.Failures == 0 || time.Since(attempt.LastFailureAt) > g.window() {
+		return time.Time{}, false
+	}
+	if attempt.Failures >= threshold {
+		return attempt.LastFailureAt.Add(time.Duration(config.LoginLockoutMinutes) * time.Minute), true
+	}
+	if !backoff {
+		return time.Time{}, false
+	}
+	maxDelay := time.Duration(config.LoginBackoffMaxSeconds) * time.Second
+	delay := maxDelay
+	if attempt.Failures < 31 {
+		if d := time.Duration(1<<uint(attempt.Failures-1)) * time.Second; d < maxDelay {
+			delay = d
+		}
+	}
+	return attempt.LastFailureAt.Add(delay), false
+}
+
+func (g *LoginGuard) check(key string, threshold int, backoff bool) error {
+	attempt, err := g.store.Get(key)
+	if err != nil {
+		return err
+	}
+	until, locked := g.blockedUntil(attempt, threshold, backoff)
+	if wait := time.Until(until); wait > 0 {
+		blocked := ErrLoginThrottled
+		if locked {
+			blocked = ErrLoginLocked
+		}
+		return blocked.With("retryAfter", int(math.Ceil(wait.Seconds())))
+	}
+	return nil
+}
+
+// Check deve ser chamado antes de conferir a senha. Falhas do backend não impedem
+// o login, para que um problema nos contadores não tire o sistema do ar.
+// O IP não tem backoff: redes do campus compartilham endereço, e um erro de
+// digitação de um aluno não deve atrasar o login dos outros.
+func (g *LoginGuard) Check(email, ip string) error {
+	for _, k := range []struct {
+		key       string
+		threshold int
+		backoff   bool
+	}{
+		{accountKey(email), config.LoginMaxFailures, true},
+		{ipKey(ip), config.LoginIPMaxFailures, false},
+	} {
+		err := g.check(k.key, k.threshold, k.backoff)
+		if errors.Is(err, ErrLoginThrottled) || errors.Is(err, ErrLoginLocked) {
+			return err
+		}
+		if err != nil {
+			log.Printf("Error reading login attempts for %s: %v", k.key, err)
+		}
+	}
+	return nil
+}
+
+// Fail registra uma tentativa malsucedida para a conta e para o IP.
+// Emails inexistentes também contam, para não revelar quais contas existem.
+func (g *LoginGuard) Fail(email, ip string) {
+	now := time.Now()
+	attempt, err := g.store.RecordFailure(accountKey(email), now, g.window())
+	if err != nil {
+		log.Printf("Error recording login failure for %s: %v", email, err)
+	} else if attempt.Failures == config.LoginMaxFailures {
+		g.notifyLocked(email, now)
+	}
+	if _, err := g.store.RecordFailure(ipKey(ip), now, g.window()); err != nil {
+		log.Printf("Error recording login failure for %s: %v", ip, err)
+	}
+}
+
+// Succeed zera o contador da conta. O do IP só expira com o tempo, para que uma
+// conta válida não sirva para liberar tentativas contra as outras.
+func (g *LoginGuard) Succeed(email string) {
+	if err := g.store.Reset(accountKey(email)); err != nil {
+		log.Printf("Error resetting login attempts for %s: %v", email, err)
+	}
+}
+
+func (g *LoginGuard) notifyLocked(email string, lockedAt time.Time) {
+	var user model.User
+	if err := g.db.Omit("AvatarData").Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).
+		First(&user).Error; err != nil {
+		return
+	}
+	until := lockedAt.Add(time.Duration(config.LoginLockoutMinutes) * time.Minute)
+	message := fmt.Sprintf("Detectamos %d tentativas de login sem sucesso na sua conta. O acesso foi bloqueado até %s. "+
+		"Se não foi você, redefina sua senha.", config.LoginMaxFailures, until.Format("02/01/2006 15:04"))
+	if err := g.notificationSvc.CreateNotification(user.ID, model.NotificationTypeSecurity,
+		"Conta bloqueada temporariamente", message); err != nil {
+		log.Printf("Error creating lockout notification for user %d: %v", user.ID, err)
+	}
+	go func() {
+		if err := mail.SendMail(user.Email, "Conta CampusCash bloqueada temporariamente",
+			fmt.Sprintf("Olá, %s!\n\n%s\n", user.Name, message)); err != nil {
+			log.Printf("Error sending lockout email to %s: %v", user.Email, err)
+		}
+	}()
+}
+
+// Unlock remove o bloqueio da conta antes do prazo (ação administrativa).
+func (g *LoginGuard) Unlock(actorID, userID uint) error {
+	var user model.User
+	if err := g.db.Omit("AvatarData").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if err := g.store.Reset(accountKey(user.Email)); err != nil {
+		return err
+	}
+	return recordAudit(g.db, actorID, "user.unlock", "user", &userID, nil)
+}
+
+// Cleanup descarta contadores que já saíram da janela.
+func (g *LoginGuard) Cleanup() (int64, error) {
+	return g.store.Prune(time.Now().Add(-g.window()))
+}
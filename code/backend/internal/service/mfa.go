@@ -0,0 +1,432 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/totp"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMFANotEnrolled      = apperror.New("mfa_not_enrolled", http.StatusBadRequest)
+	ErrMFAAlreadyEnabled   = apperror.New("mfa_already_enabled", http.StatusConflict)
+	ErrMFANotEnabled       = apperror.New("mfa_not_enabled", http.StatusBadRequest)
+	ErrMFARequired         = apperror.New("mfa_required", http.StatusForbidden)
+	ErrInvalidMFACode      = apperror.New("invalid_mfa_code", http.StatusBadRequest)
+	ErrInvalidMFAChallenge = apperror.New("invalid_mfa_challenge", http.StatusUnauthorized)
+	ErrInvalidPassword     = apperror.New("invalid_password", http.StatusBadRequest)
+)
+
+const (
+	recoveryCodeCount    = 10
+	maxChallengeAttempts = 5
+	// Aceita um passo de 30s de diferença entre o relógio do celular e o do servidor
+	totpSkew = 1
+)
+
+// MFARequired indica se a política exige o segundo fator para o papel.
+func MFARequired(role model.UserRole) bool {
+	for _, r := range config.MFARequiredRoles {
+		if model.UserRole(r) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// O segredo TOTP precisa ser lido de volta, então é cifrado em vez de hasheado.
+const mfaSecretPurpose = "mfa-secret"
+
+// Códigos de recuperação são comparados sem hífen, espaços ou diferença de caixa.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// CheckMFASecret confere na inicialização que o MFA_KEY_SECRET abre os segredos TOTP já
+// gravados; com o segredo errado, nenhum usuário com MFA conseguiria entrar.
+func CheckMFASecret(db *gorm.DB) error {
+	var creds []model.MFACredential
+	if err := db.Order("confirmed_at IS NULL").Limit(1).Find(&creds).Error; err != nil {
+		return err
+	}
+	if len(creds) == 0 {
+		return nil
+	}
+	if _, err := openSecret(mfaSecretPurpose, creds[0].SecretEncrypted); err != nil {
+		return fmt.Errorf("MFA_KEY_SECRET não abre o segredo TOTP do usuário %d: %w", creds[0].UserID, err)
+	}
+	return nil
+}
+
+type MFAService struct {
+	db *gorm.DB
+}
+
+func NewMFAService(db *gorm.DB) *MFAService {
+	return &MFAService{db: db}
+}
+
+func (s *MFAService) credential(tx *gorm.DB, userID uint) (*model.MFACredential, error) {
+	var creds []model.MFACredential
+	if err := tx.Where("user_id = ?", userID).Limit(1).Find(&creds).Error; err != nil {
+		return nil, err
+	}
+	if len(creds) == 0 {
+		return nil, nil
+	}
+	return &creds[0], nil
+}
+
+func (s *MFAService) Enabled(userID uint) (bool, error) {
+	cred, err := s.credential(s.db, userID)
+	if err != nil {
+		return false, err
+	}
+	return cred != nil && cred.ConfirmedAt != nil, nil
+}
+
+func (s *MFAService) Status(userID uint) (*dto.MFAStatusDTO, error) {
+	var user model.User
+	if err := s.db.Omit("AvatarData").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	enabled, err := s.Enabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := dto.MFAStatusDTO{Enabled: enabled, Required: MFARequired(user.Role)}
+	if err := s.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&status.RecoveryCodesLeft).Error; err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// BeginEnrollment gera um novo segredo pendente. Enquanto não for confirmado com
+// um código válido, o login continua funcionando só com a senha.
+func (s *MFAService) BeginEnrollment(userID uint) (*dto.MFAEnrollmentDTO, error) {
+	var user model.User
+	if err := s.db.Omit("AvatarData").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := sealSecret(mfaSecretPurpose, secret)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		cred, err := s.credential(tx, userID)
+		if err != nil {
+			return err
+		}
+		if cred != nil && cred.ConfirmedAt != nil {
+			return ErrMFAAlreadyEnabled
+		}
+		return tx.Save(&model.MFACredential{UserID: userID, SecretEncrypted: sealed}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MFAEnrollmentDTO{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(config.MFAIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment ativa o segundo fator e devolve os códigos de recuperação,
+// que só são exibidos esta vez.
+func (s *MFAService) ConfirmEnrollment(userID uint, code string) (*dto.RecoveryCodesDTO, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.confirm(tx, userID, code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+func (s *MFAService) confirm(tx *gorm.DB, userID uint, code string) ([]string, error) {
+	cred, err := s.credential(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cred == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if cred.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if err := s.checkTOTP(tx, cred, code); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&model.MFACredential{}).Where("user_id = ?", userID).
+		Update("confirmed_at", time.Now()).Error; err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(tx, userID)
+}
+
+// checkTOTP valida o código e registra o passo usado, recusando códigos repetidos.
+func (s *MFAService) checkTOTP(tx *gorm.DB, cred *model.MFACredential, code string) error {
+	secret, err := openSecret(mfaSecretPurpose, cred.SecretEncrypted)
+	if err != nil {
+		return err
+	}
+	counter, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	res := tx.Model(&model.MFACredential{}).
+		Where("user_id = ? AND last_counter < ?", cred.UserID, counter).
+		Update("last_counter", counter)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// verify aceita tanto o código do autenticador quanto um código de recuperação ainda não usado.
+func (s *MFAService) verify(tx *gorm.DB, userID uint, code string) error {
+	cred, err := s.credential(tx, userID)
+	if err != nil {
+		return err
+	}
+	if cred == nil || cred.ConfirmedAt == nil {
+		return ErrMFANotEnabled
+	}
+	if len(strings.TrimSpace(code)) == totp.Digits {
+		return s.checkTOTP(tx, cred, code)
+	}
+	res := tx.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := randomToken(5)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&model.RecoveryCode{UserID: userID, CodeHash: hashToken(raw)}).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes invalida os códigos anteriores e emite uma nova lista.
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) (*dto.RecoveryCodesDTO, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.verify(tx, userID, code); err != nil {
+			return err
+		}
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesDTO{RecoveryCodes: codes}, nil
+}
+
+// Disable remove o segundo fator. Exige a senha e um código válido, e não é
+// permitido para papéis em que a política torna o MFA obrigatório.
+func (s *MFAService) Disable(userID uint, password, code string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Omit("AvatarData").First(&user, userID).Error; err != nil {
+			return err
+		}
+		if MFARequired(user.Role) {
+			return ErrMFARequired
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+			return ErrInvalidPassword
+		}
+		if err := s.verify(tx, userID, code); err != nil {
+			return err
+		}
+		return removeMFA(tx, userID)
+	})
+}
+
+func removeMFA(tx *gorm.DB, userID uint) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&model.MFACredential{}).Error
+}
+
+// NeedsChallenge indica se o login com senha deve parar no desafio do segundo fator.
+func (s *MFAService) NeedsChallenge(user *model.User) (bool, error) {
+	if MFARequired(user.Role) {
+		return true, nil
+	}
+	return s.Enabled(user.ID)
+}
+
+// Challenge emite o token de curta duração trocado por uma sessão em CompleteChallenge.
+// Se a política exige MFA e o usuário ainda não cadastrou, o mesmo token serve para o cadastro.
+func (s *MFAService) Challenge(user *model.User) (*dto.MFAChallengeDTO, error) {
+	enabled, err := s.Enabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	ttl := time.Duration(config.MFAChallengeMinutes) * time.Minute
+	if err := s.db.Create(&model.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return &dto.MFAChallengeDTO{
+		MFARequired:    true,
+		SetupRequired:  !enabled,
+		ChallengeToken: token,
+		ExpiresIn:      int(ttl.Seconds()),
+	}, nil
+}
+
+func (s *MFAService) findChallenge(tx *gorm.DB, token string) (*model.MFAChallenge, error) {
+	var challenge model.MFAChallenge
+	err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?",
+		hashToken(token), time.Now(), maxChallengeAttempts).First(&challenge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidMFAChallenge
+	}
+	return &challenge, err
+}
+
+// ChallengeUser identifica o dono do desafio, sem consumi-lo.
+func (s *MFAService) ChallengeUser(token string) (*model.User, error) {
+	challenge, err := s.findChallenge(s.db, token)
+	if err != nil {
+		return nil, err
+	}
+	var user model.User
+	if err := s.db.Omit("AvatarData").First(&user, challenge.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ChallengeEnrollment inicia o cadastro obrigatório a partir de um desafio de login.
+func (s *MFAService) ChallengeEnrollment(token string) (*dto.MFAEnrollmentDTO, error) {
+	challenge, err := s.findChallenge(s.db, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.BeginEnrollment(challenge.UserID)
+}
+
+// CompleteChallenge confere o segundo fator e consome o desafio. Quando o desafio
+// também concluiu um cadastro, os novos códigos de recuperação são devolvidos.
+func (s *MFAService) CompleteChallenge(token, code string) (*model.User, []string, error) {
+	challenge, err := s.findChallenge(s.db, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	// A tentativa é contada fora da transação para valer mesmo quando o código está errado
+	res := s.db.Model(&model.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ?", challenge.ID, maxChallengeAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+
+	var user model.User
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		cred, err := s.credential(tx, challenge.UserID)
+		if err != nil {
+			return err
+		}
+		if cred != nil && cred.ConfirmedAt != nil {
+			err = s.verify(tx, challenge.UserID, code)
+		} else {
+			codes, err = s.confirm(tx, challenge.UserID, code)
+		}
+		if err != nil {
+			return err
+		}
+		used := tx.Model(&model.MFAChallenge{}).
+			Where("id = ? AND used_at IS NULL", challenge.ID).
+			Update("used_at", time.Now())
+		if used.Error != nil {
+			return used.Error
+		}
+		if used.RowsAffected == 0 {
+			return ErrInvalidMFAChallenge
+		}
+		return tx.Omit("AvatarData").First(&user, challenge.UserID).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &user, codes, nil
+}
+
+// Cleanup remove desafios de login vencidos.
+func (s *MFAService) Cleanup() (int64, error) {
+	res := s.db.Where("expires_at < ?", time.Now().Add(-time.Hour)).Delete(&model.MFAChallenge{})
+	return res.RowsAffected, res.Error
+}
+
+// ResetMFA remove o segundo fator de um usuário que perdeu o autenticador e os códigos.
+func (s *AdminService) ResetMFA(actorID, userID uint) error {
+	if actorID == userID {
+		return ErrSelfModification
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.findUser(tx, userID); err != nil {
+			return err
+		}
+		if err := removeMFA(tx, userID); err != nil {
+			return err
+		}
+		return recordAudit(tx, actorID, "user.reset_mfa", "user", &userID, nil)
+	})
+}
@@ -4,7 +4,7 @@ import (
 	"campuscash-backend/internal/dto"
 	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/repository"
-	"log"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -13,6 +13,7 @@ type ProfessorService interface {
 	GetProfile(id uint) (*dto.ProfessorProfileDTO, error)
 	GetBalance(id uint) (uint, error)
 	ListStudents(professorID uint) ([]model.User, error)
+	SearchStudents(professorID uint, term string) ([]dto.StudentSearchDTO, error)
 	UpdateProfile(id uint, input dto.ProfessorUpdateDTO) (*dto.ProfessorProfileDTO, error)
 	GetStatistics(id uint) (*dto.ProfessorStatisticsDTO, error)
 }
@@ -36,9 +37,9 @@ func (s *professorService) GetProfile(id uint) (*dto.ProfessorProfileDTO, error)
 		ID:          prof.ID,
 		Name:        prof.Name,
 		Email:       prof.Email,
-		CPF:         *prof.CPF,
-		Department:  *prof.Department,
-		Institution: *prof.Institution,
+		CPF:         stringValue(prof.CPF),
+		Department:  stringValue(prof.Department),
+		Institution: institutionName(s.db, prof.InstitutionID),
 		Balance:     prof.Balance,
 	}, nil
 }
@@ -51,15 +52,37 @@ func (s *professorService) GetBalance(id uint) (uint, error) {
 	return p.Balance, nil
 }
 
+// ListStudents devolve só os alunos que o professor pode premiar (ver RewardableStudents).
 func (s *professorService) ListStudents(professorID uint) ([]model.User, error) {
-	// Sempre buscar todos os alunos, independente de instituição
-	students, err := s.repo.FindAllStudents("")
+	prof, err := s.repo.FindByID(professorID)
 	if err != nil {
-		log.Printf("Error fetching students: %v", err)
-		return []model.User{}, nil
+		return nil, err
 	}
-	log.Printf("Found %d students", len(students))
-	return students, nil
+	query, err := RewardableStudents(s.db, prof)
+	if err != nil {
+		return nil, err
+	}
+	var students []model.User
+	err = query.Order("users.name").Find(&students).Error
+	return students, err
+}
+
+func (s *professorService) SearchStudents(professorID uint, term string) ([]dto.StudentSearchDTO, error) {
+	prof, err := s.repo.FindByID(professorID)
+	if err != nil {
+		return nil, err
+	}
+	query, err := RewardableStudents(s.db, prof)
+	if err != nil {
+		return nil, err
+	}
+	like := "%" + strings.ToLower(term) + "%"
+	result := []dto.StudentSearchDTO{}
+	err = query.Where("(LOWER(users.name) LIKE ? OR LOWER(users.email) LIKE ?)", like, like).
+		Order("users.name").
+		Select("users.id, users.name, users.email").
+		Scan(&result).Error
+	return result, err
 }
 
 func (s *professorService) UpdateProfile(id uint, input dto.ProfessorUpdateDTO) (*dto.ProfessorProfileDTO, error) {
@@ -67,7 +90,9 @@ func (s *professorService) UpdateProfile(id uint, input dto.ProfessorUpdateDTO)
 	if err != nil {
 		return nil, err
 	}
-	
+	if err := EnsureUniqueUser(s.db, id, input.Email, input.CPF, "cpf"); err != nil {
+		return nil, err
+	}
 
 	if input.Name != "" {
 		professor.Name = input.Name
@@ -81,21 +106,21 @@ func (s *professorService) UpdateProfile(id uint, input dto.ProfessorUpdateDTO)
 	if input.Department != "" {
 		professor.Department = &input.Department
 	}
-	if input.Institution != "" {
-		professor.Institution = &input.Institution
+	if err := changeInstitution(s.db, professor, input.Institution); err != nil {
+		return nil, err
 	}
 	
 	if err := s.repo.Update(professor); err != nil {
-		return nil, err
+		return nil, UniqueViolation(err, "cpf")
 	}
 	
 	return &dto.ProfessorProfileDTO{
 		ID:          professor.ID,
 		Name:        professor.Name,
 		Email:       professor.Email,
-		CPF:         *professor.CPF,
-		Department:  *professor.Department,
-		Institution: *professor.Institution,
+		CPF:         stringValue(professor.CPF),
+		Department:  stringValue(professor.Department),
+		Institution: institutionName(s.db, professor.InstitutionID),
 		Balance:     professor.Balance,
 	}, nil
 }
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReconciliationService struct {
+	db *gorm.DB
+}
+
+func NewReconciliationService(db *gorm.DB) *ReconciliationService {
+	return &ReconciliationService{db: db}
+}
+
+type flowTotal struct {
+	UserID uint
+	Type   model.TransactionType
+	Total  uint
+}
+
+// ledgerOnlyEntries são os lançamentos que movimentam saldo sem model.Transaction: a
+// abertura de conta e os ajustes da própria conciliação.
+var ledgerOnlyEntries = []model.TransactionType{model.OpeningBalance, model.Adjustment}
+
+// Run recalcula o saldo esperado de cada usuário a partir de model.Transaction, somado
+// ao saldo de abertura, e compara com User.Balance. Com repair=true, cada divergência é
+// corrigida com um lançamento de ajuste contra a conta de emissão, dentro de uma
+// transação. Os ajustes não entram no esperado: eles levam o saldo até ele.
+func (s *ReconciliationService) Run(repair bool) (*dto.ReconciliationReportDTO, error) {
+	var users []model.User
+	if err := s.db.Select("id", "name", "role", "balance").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	var inflows, outflows []flowTotal
+	if err := s.db.Model(&model.Transaction{}).
+		Select("transactions.to_user_id as user_id, transactions.type, COALESCE(SUM(transactions.amount), 0) as total").
+		Scopes(s.afterOpening("transactions.to_user_id")).
+		Group("transactions.to_user_id, transactions.type").
+		Scan(&inflows).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&model.Transaction{}).
+		Select("transactions.from_user_id as user_id, transactions.type, COALESCE(SUM(transactions.amount), 0) as total").
+		Scopes(s.afterOpening("transactions.from_user_id")).
+		Group("transactions.from_user_id, transactions.type").
+		Scan(&outflows).Error; err != nil {
+		return nil, err
+	}
+
+	var openings []struct {
+		UserID uint
+		Total  int64
+	}
+	if err := s.db.Table("postings").
+		Select("ledger_accounts.user_id, COALESCE(SUM(postings.amount), 0) as total").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.entry_id").
+		Joins("JOIN ledger_accounts ON ledger_accounts.id = postings.account_id").
+		Where("ledger_accounts.user_id IS NOT NULL AND journal_entries.type = ?", model.OpeningBalance).
+		Group("ledger_accounts.user_id").
+		Scan(&openings).Error; err != nil {
+		return nil, err
+	}
+	opening := make(map[uint]int64, len(openings))
+	for _, o := range openings {
+		opening[o.UserID] = o.Total
+	}
+
+	in := make(map[uint]map[model.TransactionType]uint)
+	out := make(map[uint]map[model.TransactionType]uint)
+	for _, f := range inflows {
+		if in[f.UserID] == nil {
+			in[f.UserID] = make(map[model.TransactionType]uint)
+		}
+		in[f.UserID][f.Type] += f.Total
+	}
+	for _, f := range outflows {
+		if out[f.UserID] == nil {
+			out[f.UserID] = make(map[model.TransactionType]uint)
+		}
+		out[f.UserID][f.Type] += f.Total
+	}
+
+	report := &dto.ReconciliationReportDTO{
+		GeneratedAt:   time.Now(),
+		UsersChecked:  len(users),
+		RepairApplied: repair,
+		Discrepancies: []dto.ReconciliationDiscrepancyDTO{},
+	}
+
+	for _, u := range users {
+		expected := opening[u.ID]
+		for _, total := range in[u.ID] {
+			expected += int64(total)
+		}
+		for _, total := range out[u.ID] {
+			expected -= int64(total)
+		}
+		if expected == int64(u.Balance) {
+			continue
+		}
+
+		d := dto.ReconciliationDiscrepancyDTO{
+			UserID:     u.ID,
+			Name:       u.Name,
+			Role:       u.Role,
+			Balance:    u.Balance,
+			Expected:   expected,
+			Difference: int64(u.Balance) - expected,
+			GivesIn:    in[u.ID][model.GiveCoins],
+			GivesOut:   out[u.ID][model.GiveCoins],
+			RedeemsIn:  in[u.ID][model.RedeemCoins],
+			RedeemsOut: out[u.ID][model.RedeemCoins],
+		}
+		if err := s.loadOffendingRows(&d); err != nil {
+			return nil, err
+		}
+		if repair {
+			if err := s.repair(u.ID, expected); err != nil {
+				d.RepairError = err.Error()
+			} else {
+				d.Repaired = true
+			}
+		}
+		report.Discrepancies = append(report.Discrepancies, d)
+	}
+
+	return report, nil
+}
+
+// afterOpening restringe as transações às que não estão no saldo de abertura: o saldo
+// de abertura registra User.Balance quando a conta do livro-razão foi criada, então
+// transações anteriores a ela e sem lançamento (como as do seed) já estão nele.
+func (s *ReconciliationService) afterOpening(userColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Joins("LEFT JOIN ledger_accounts ON ledger_accounts.user_id = "+userColumn).
+			Where(userColumn+" IS NOT NULL").
+			Where("ledger_accounts.id IS NULL OR transactions.created_at >= ledger_accounts.created_at OR transactions.id IN (?)",
+				s.db.Model(&model.JournalEntry{}).Select("transaction_id").Where("transaction_id IS NOT NULL"))
+	}
+}
+
+func (s *ReconciliationService) loadOffendingRows(d *dto.ReconciliationDiscrepancyDTO) error {
+	d.UnpostedTransactions = []model.Transaction{}
+	if err := s.db.Where("(from_user_id = ? OR to_user_id = ?) AND id NOT IN (?)", d.UserID, d.UserID,
+		s.db.Model(&model.JournalEntry{}).Select("transaction_id").Where("transaction_id IS NOT NULL")).
+		Where("created_at >= (?)", s.db.Model(&model.LedgerAccount{}).Select("COALESCE(MIN(created_at), 0)").Where("user_id = ?", d.UserID)).
+		Order("created_at desc").
+		Find(&d.UnpostedTransactions).Error; err != nil {
+		return err
+	}
+
+	d.UnrecordedEntries = []dto.LedgerPostingDTO{}
+	return s.db.Table("postings").
+		Select("postings.entry_id, postings.amount, journal_entries.type, journal_entries.transaction_id, journal_entries.memo, postings.created_at").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.entry_id").
+		Joins("JOIN ledger_accounts ON ledger_accounts.id = postings.account_id").
+		Where("ledger_accounts.user_id = ? AND journal_entries.transaction_id IS NULL AND journal_entries.type NOT IN ?", d.UserID, ledgerOnlyEntries).
+		Order("postings.id desc").
+		Scan(&d.UnrecordedEntries).Error
+}
+
+// repair leva o saldo do usuário ao valor derivado do histórico de transações; na
+// execução seguinte a conta já fecha.
+func (s *ReconciliationService) repair(userID uint, expected int64) error {
+	if expected < 0 {
+		return fmt.Errorf("histórico resulta em saldo negativo (%d); correção manual necessária", expected)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		acc, err := AccountForUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		delta := expected - acc.Balance
+		if delta == 0 {
+			// O livro-razão já está certo; só a projeção em User.Balance divergiu
+			return tx.Model(&model.User{}).Where("id = ?", userID).Update("balance", uint(acc.Balance)).Error
+		}
+		mint, err := mintAccount(tx)
+		if err != nil {
+			return err
+		}
+		entry := &model.JournalEntry{
+			Type: model.Adjustment,
+			Memo: fmt.Sprintf("Ajuste de conciliação: saldo %d -> %d", acc.Balance, expected),
+		}
+		return PostEntry(tx, entry, []model.Posting{
+			{AccountID: mint.ID, Amount: -delta},
+			{AccountID: acc.ID, Amount: delta},
+		})
+	})
+}
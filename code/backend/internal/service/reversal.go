@@ -0,0 +1,186 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTransactionNotFound   = apperror.New("transaction_not_found", http.StatusNotFound)
+	ErrNotTransactionOwner   = apperror.New("not_transaction_owner", http.StatusForbidden)
+	ErrNotReversible         = apperror.New("not_reversible", http.StatusUnprocessableEntity)
+	ErrReversalWindowExpired = apperror.New("reversal_window_expired", http.StatusUnprocessableEntity)
+	ErrAlreadyReversed       = apperror.New("already_reversed", http.StatusConflict)
+	ErrCouponAlreadyUsed     = apperror.New("coupon_already_used", http.StatusConflict)
+	ErrCoinsSpent            = ErrInsufficientBalance.WithKey("insufficient_balance.coins_spent")
+)
+
+type ReversalService struct {
+	db              *gorm.DB
+	notificationSvc *NotificationService
+}
+
+func NewReversalService(db *gorm.DB, notificationSvc *NotificationService) *ReversalService {
+	return &ReversalService{db: db, notificationSvc: notificationSvc}
+}
+
+// ReverseGive desfaz um envio de moedas do professor para o aluno. Só é permitido
+// dentro de config.ReversalWindowHours e se o aluno não tiver mexido nas moedas daquele
+// envio; saldo vindo de outros envios não serve para cobrir o estorno.
+func (s *ReversalService) ReverseGive(professorID, transactionID uint, reason string) (*model.Transaction, error) {
+	var original model.Transaction
+	var reversal model.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := loadReversible(tx, transactionID, &original); err != nil {
+			return err
+		}
+		if original.Type != model.GiveCoins || original.FromUserID == nil || original.ToUserID == nil {
+			return ErrNotReversible
+		}
+		if *original.FromUserID != professorID {
+			return ErrNotTransactionOwner
+		}
+		window := time.Duration(config.ReversalWindowHours) * time.Hour
+		if time.Since(original.CreatedAt) > window {
+			return ErrReversalWindowExpired
+		}
+		if err := ensureGiveUnspent(tx, &original); err != nil {
+			return err
+		}
+
+		reversal = model.Transaction{
+			FromUserID:   original.ToUserID,
+			ToUserID:     original.FromUserID,
+			Amount:       original.Amount,
+			Message:      reason,
+			Type:         model.Reversal,
+			ReversalOfID: &original.ID,
+		}
+		return RecordTransfer(tx, &reversal)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyBoth(&original, model.NotificationTypeReversal, "Envio de Moedas Estornado",
+		fmt.Sprintf("O envio de %d moedas foi estornado: %s", original.Amount, reason))
+	return &reversal, nil
+}
+
+// RefundRedemption devolve ao aluno as moedas de um resgate que a empresa não pôde
+// honrar e invalida o cupom associado.
+func (s *ReversalService) RefundRedemption(companyID, transactionID uint, reason string) (*model.Transaction, error) {
+	var original model.Transaction
+	var refund *model.Transaction
+	replenished := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := loadReversible(tx, transactionID, &original); err != nil {
+			return err
+		}
+		if original.Type != model.RedeemCoins || original.FromUserID == nil || original.ToUserID == nil {
+			return ErrNotReversible
+		}
+		if *original.ToUserID != companyID {
+			return ErrNotTransactionOwner
+		}
+
+		// Cupons vencidos sem reembolso automático ainda podem ser reembolsados pela empresa
+		if original.Code != nil {
+			err := transitionCoupon(tx, *original.Code, model.CouponRefunded, time.Now(),
+				model.CouponIssued, model.CouponExpired)
+			if err != nil && !errors.Is(err, ErrCouponNotFound) {
+				return err
+			}
+		}
+
+		var err error
+		refund, replenished, err = refundRedeem(tx, &original, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replenished {
+		go notifyWaitlist(s.db, s.notificationSvc, *original.RewardID)
+	}
+
+	s.notifyBoth(&original, model.NotificationTypeRefund, "Resgate Reembolsado",
+		fmt.Sprintf("O resgate de %d moedas foi reembolsado: %s", original.Amount, reason))
+	return refund, nil
+}
+
+// refundRedeem lança a devolução das moedas de um resgate e repõe a unidade no estoque.
+// replenished indica que a vantagem estava esgotada e voltou ao catálogo.
+func refundRedeem(tx *gorm.DB, original *model.Transaction, reason string) (*model.Transaction, bool, error) {
+	refund := &model.Transaction{
+		FromUserID:   original.ToUserID,
+		ToUserID:     original.FromUserID,
+		Amount:       original.Amount,
+		Message:      reason,
+		Type:         model.Refund,
+		RewardID:     original.RewardID,
+		Code:         original.Code,
+		ReversalOfID: &original.ID,
+	}
+	if err := RecordTransfer(tx, refund); err != nil {
+		return nil, false, err
+	}
+	if original.RewardID == nil {
+		return refund, false, nil
+	}
+	replenished, err := releaseStock(tx, *original.RewardID)
+	return refund, replenished, err
+}
+
+// ensureGiveUnspent exige que o lote criado pelo envio esteja intacto: nada gasto,
+// consumido ou expirado.
+func ensureGiveUnspent(tx *gorm.DB, original *model.Transaction) error {
+	var count int64
+	if err := tx.Model(&model.CoinLot{}).
+		Where("transaction_id = ? AND user_id = ? AND amount = ? AND remaining = amount",
+			original.ID, *original.ToUserID, original.Amount).
+		Where("expired_at IS NULL AND expires_at > ?", time.Now()).
+		Where("NOT EXISTS (SELECT 1 FROM coin_lot_consumptions WHERE coin_lot_consumptions.lot_id = coin_lots.id)").
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCoinsSpent
+	}
+	return nil
+}
+
+func loadReversible(tx *gorm.DB, transactionID uint, original *model.Transaction) error {
+	if err := tx.First(original, transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTransactionNotFound
+		}
+		return err
+	}
+	var count int64
+	if err := tx.Model(&model.Transaction{}).Where("reversal_of_id = ?", original.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAlreadyReversed
+	}
+	return nil
+}
+
+func (s *ReversalService) notifyBoth(original *model.Transaction, notificationType model.NotificationType, title, message string) {
+	if s.notificationSvc == nil {
+		return
+	}
+	for _, userID := range []*uint{original.FromUserID, original.ToUserID} {
+		go func(id uint) {
+			_ = s.notificationSvc.CreateNotification(id, notificationType, title, message)
+		}(*userID)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/model"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type reversalFixture struct {
+	db               *gorm.DB
+	profA, profB     model.User
+	student, company model.User
+}
+
+func newReversalFixture(t *testing.T) *reversalFixture {
+	t.Helper()
+	dsn := config.SQLiteDSN("file:" + filepath.Join(t.TempDir(), "reversal.db"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&model.User{}, &model.Institution{}, &model.Transaction{}, &model.LedgerAccount{}, &model.JournalEntry{}, &model.Posting{}, &model.CoinLot{}, &model.CoinLotConsumption{}); err != nil {
+		t.Fatal(err)
+	}
+	config.CoinExpiryDays = 180
+	config.ReversalWindowHours = 24
+	f := &reversalFixture{
+		db:      db,
+		profA:   model.User{Name: "Prof A", Email: "a@test", Role: model.ProfessorRole, Balance: 100},
+		profB:   model.User{Name: "Prof B", Email: "b@test", Role: model.ProfessorRole, Balance: 100},
+		student: model.User{Name: "Aluno", Email: "aluno@test", Role: model.StudentRole},
+		company: model.User{Name: "Loja", Email: "loja@test", Role: model.CompanyRole},
+	}
+	for _, u := range []*model.User{&f.profA, &f.profB, &f.student, &f.company} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func (f *reversalFixture) transfer(t *testing.T, from, to *model.User, amount uint, kind model.TransactionType) model.Transaction {
+	t.Helper()
+	tr := model.Transaction{FromUserID: &from.ID, ToUserID: &to.ID, Amount: amount, Type: kind}
+	if err := f.db.Transaction(func(tx *gorm.DB) error { return RecordTransfer(tx, &tr) }); err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+func (f *reversalFixture) balance(t *testing.T, u *model.User) uint {
+	t.Helper()
+	var stored model.User
+	if err := f.db.First(&stored, u.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return stored.Balance
+}
+
+func TestReverseGiveRejectsSpentCoins(t *testing.T) {
+	f := newReversalFixture(t)
+	svc := NewReversalService(f.db, nil)
+
+	give := f.transfer(t, &f.profA, &f.student, 30, model.GiveCoins)
+	f.transfer(t, &f.student, &f.company, 30, model.RedeemCoins)
+	// Saldo suficiente, mas vindo de outro professor
+	f.transfer(t, &f.profB, &f.student, 50, model.GiveCoins)
+
+	if _, err := svc.ReverseGive(f.profA.ID, give.ID, "engano"); !errors.Is(err, ErrCoinsSpent) {
+		t.Fatalf("esperava ErrCoinsSpent, obteve %v", err)
+	}
+	if got := f.balance(t, &f.student); got != 50 {
+		t.Fatalf("o saldo do aluno não deveria mudar: esperava 50, obteve %d", got)
+	}
+}
+
+func TestReverseGiveRejectsPartiallySpentCoins(t *testing.T) {
+	f := newReversalFixture(t)
+	svc := NewReversalService(f.db, nil)
+
+	give := f.transfer(t, &f.profA, &f.student, 30, model.GiveCoins)
+	f.transfer(t, &f.profB, &f.student, 50, model.GiveCoins)
+	// FIFO: o resgate consome parte do lote do professor A
+	f.transfer(t, &f.student, &f.company, 20, model.RedeemCoins)
+
+	if _, err := svc.ReverseGive(f.profA.ID, give.ID, "engano"); !errors.Is(err, ErrCoinsSpent) {
+		t.Fatalf("esperava ErrCoinsSpent, obteve %v", err)
+	}
+}
+
+func TestReverseGiveConsumesOwnLot(t *testing.T) {
+	f := newReversalFixture(t)
+	svc := NewReversalService(f.db, nil)
+
+	other := f.transfer(t, &f.profB, &f.student, 50, model.GiveCoins)
+	give := f.transfer(t, &f.profA, &f.student, 30, model.GiveCoins)
+
+	if _, err := svc.ReverseGive(f.profA.ID, give.ID, "engano"); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.balance(t, &f.student); got != 50 {
+		t.Fatalf("esperava saldo 50 após o estorno, obteve %d", got)
+	}
+	if got := f.balance(t, &f.profA); got != 100 {
+		t.Fatalf("esperava as 30 moedas de volta ao professor, saldo %d", got)
+	}
+	var lots []model.CoinLot
+	f.db.Where("user_id = ?", f.student.ID).Find(&lots)
+	for _, lot := range lots {
+		switch *lot.TransactionID {
+		case give.ID:
+			if lot.Remaining != 0 {
+				t.Fatalf("o lote estornado deveria zerar, restam %d", lot.Remaining)
+			}
+		case other.ID:
+			if lot.Remaining != 50 {
+				t.Fatalf("o lote do outro professor não deveria ser tocado, restam %d", lot.Remaining)
+			}
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"campuscash-backend/internal/dto"
 	"campuscash-backend/internal/model"
 	"campuscash-backend/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 type RewardService interface {
@@ -13,25 +15,42 @@ type RewardService interface {
 
 type rewardService struct {
     repo repository.RewardRepository
+    db   *gorm.DB
 }
 
-func NewRewardService(repo repository.RewardRepository) RewardService {
-    return &rewardService{repo}
+func NewRewardService(repo repository.RewardRepository, db *gorm.DB) RewardService {
+    return &rewardService{repo, db}
 }
 
 func (s *rewardService) CreateReward(input dto.RewardCreateDTO) (*model.Reward, error) {
     reward := &model.Reward{
-        Title:       input.Title,
-        Description: input.Description,
-        Cost:        input.Cost,
-        Category:    input.Category,
-        CompanyID:   input.CompanyID,
-        Active:      true,
+        Title:         input.Title,
+        Description:   input.Description,
+        Cost:          input.Cost,
+        Category:      input.Category,
+        CompanyID:     input.CompanyID,
+        Active:        true,
+        Stock:         input.Stock,
+        MaxPerStudent: input.MaxPerStudent,
+        PeriodLimit:   input.PeriodLimit,
+        PeriodDays:    input.PeriodDays,
+        PublishAt:       input.PublishAt,
+        ExpireAt:        input.ExpireAt,
+        CouponValidDays: input.CouponValidDays,
+        RefundOnExpiry:  input.RefundOnExpiry,
     }
-    err := s.repo.Create(reward)
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := repository.NewRewardRepository(tx).Create(reward); err != nil {
+            return err
+        }
+        if err := SetRewardInstitutions(tx, reward.ID, input.InstitutionIDs); err != nil {
+            return err
+        }
+        return SetRewardBranches(tx, reward.CompanyID, reward.ID, input.BranchIDs)
+    })
     return reward, err
 }
 
 func (s *rewardService) ListCompanyRewards(companyID uint) ([]model.Reward, error) {
     return s.repo.ListByCompany(companyID)
-}
\ No newline at end of file
+}
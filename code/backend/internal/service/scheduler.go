@@ -0,0 +1,278 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/schedule"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrJobNotFound = apperror.New("job_not_found", http.StatusNotFound)
+	ErrJobRunning  = apperror.New("job_running", http.StatusConflict)
+)
+
+// JobFunc executa um job. O resultado, se houver, é gravado em JSON no histórico.
+type JobFunc func() (interface{}, error)
+
+type scheduledJob struct {
+	name      string
+	spec      string
+	schedule  schedule.Schedule
+	fn        JobFunc
+	nextRunAt time.Time
+}
+
+type Scheduler struct {
+	db       *gorm.DB
+	instance string
+	jobs     []*scheduledJob
+}
+
+func NewScheduler(db *gorm.DB) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{db: db, instance: fmt.Sprintf("%s-%d", host, os.Getpid())}
+}
+
+// Register adiciona um job com uma expressão cron. Deve ser chamado antes de Start.
+func (s *Scheduler) Register(name, spec string, fn JobFunc) error {
+	sched, err := schedule.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+
+	now := time.Now()
+	state := model.ScheduledJob{Name: name}
+	if err := s.db.Where(model.ScheduledJob{Name: name}).
+		Attrs(model.ScheduledJob{Schedule: spec, NextRunAt: sched.Next(now)}).
+		FirstOrCreate(&state).Error; err != nil {
+		return err
+	}
+	if state.Schedule != spec {
+		state.Schedule = spec
+		state.NextRunAt = sched.Next(now)
+		if err := s.db.Model(&model.ScheduledJob{}).Where("name = ?", name).
+			Updates(map[string]interface{}{"schedule": spec, "next_run_at": state.NextRunAt}).Error; err != nil {
+			return err
+		}
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{name: name, spec: spec, schedule: sched, fn: fn, nextRunAt: state.NextRunAt})
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	// Execuções que ficaram "running" sem lease ativo foram interrompidas (ex.: réplica reiniciada)
+	s.db.Model(&model.JobRun{}).
+		Where("status = ? AND job_name IN (?)", model.JobRunning,
+			s.db.Model(&model.ScheduledJob{}).Select("name").Where("lease_expires_at < ?", time.Now())).
+		Updates(map[string]interface{}{"status": model.JobFailed, "error": "execução interrompida"})
+
+	ticker := time.NewTicker(time.Second)
+	go func() {
+		for now := range ticker.C {
+			for _, j := range s.jobs {
+				if !now.Before(j.nextRunAt) {
+					s.runScheduled(j, now)
+				}
+			}
+		}
+	}()
+	log.Printf("Job scheduler started - %d jobs registered (instance %s)", len(s.jobs), s.instance)
+}
+
+func (s *Scheduler) leaseDuration() time.Duration {
+	return time.Duration(config.JobLeaseSeconds) * time.Second
+}
+
+func (s *Scheduler) runScheduled(j *scheduledJob, now time.Time) {
+	next := j.schedule.Next(now)
+	// Só executa a réplica que conseguir avançar next_run_at e tomar o lease
+	res := s.db.Model(&model.ScheduledJob{}).
+		Where("name = ? AND next_run_at <= ? AND lease_expires_at < ?", j.name, now, now).
+		Updates(map[string]interface{}{
+			"next_run_at":      next,
+			"lease_owner":      s.instance,
+			"lease_expires_at": now.Add(s.leaseDuration()),
+		})
+	if res.Error != nil {
+		log.Printf("Error acquiring lease for job %s: %v", j.name, res.Error)
+		return
+	}
+	if res.RowsAffected == 0 {
+		var state model.ScheduledJob
+		if err := s.db.First(&state, "name = ?", j.name).Error; err == nil {
+			j.nextRunAt = state.NextRunAt
+		}
+		return
+	}
+	j.nextRunAt = next
+	go s.execute(j, model.ScheduledTrigger)
+}
+
+// Trigger executa um job imediatamente, respeitando o lease, e retorna a execução registrada.
+func (s *Scheduler) Trigger(name string) (*dto.JobRunDTO, error) {
+	j := s.find(name)
+	if j == nil {
+		return nil, ErrJobNotFound
+	}
+	now := time.Now()
+	res := s.db.Model(&model.ScheduledJob{}).
+		Where("name = ? AND lease_expires_at < ?", name, now).
+		Updates(map[string]interface{}{"lease_owner": s.instance, "lease_expires_at": now.Add(s.leaseDuration())})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrJobRunning
+	}
+
+	run := s.execute(j, model.ManualTrigger)
+	if run == nil {
+		return nil, fmt.Errorf("falha ao registrar execução do job %s", name)
+	}
+	result := toJobRunDTO(*run)
+	return &result, nil
+}
+
+// TriggerAs é o disparo manual feito por um administrador; fica registrado na auditoria.
+func (s *Scheduler) TriggerAs(actorID uint, name string) (*dto.JobRunDTO, error) {
+	run, err := s.Trigger(name)
+	if err != nil {
+		return nil, err
+	}
+	details := map[string]interface{}{"job": name, "status": run.Status}
+	if err := recordAudit(s.db, actorID, "job.trigger", "job_run", &run.ID, details); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *Scheduler) execute(j *scheduledJob, trigger model.JobTrigger) *model.JobRun {
+	defer s.release(j.name)
+
+	run := &model.JobRun{
+		JobName:   j.name,
+		Trigger:   trigger,
+		Status:    model.JobRunning,
+		Instance:  s.instance,
+		StartedAt: time.Now(),
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		log.Printf("Error recording run of job %s: %v", j.name, err)
+		return nil
+	}
+
+	result, err := s.call(j)
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = model.JobSucceeded
+	if err != nil {
+		run.Status = model.JobFailed
+		run.Error = err.Error()
+		log.Printf("Job %s failed: %v", j.name, err)
+	}
+	if result != nil {
+		if data, err := json.Marshal(result); err == nil {
+			run.Result = string(data)
+		}
+	}
+	if err := s.db.Save(run).Error; err != nil {
+		log.Printf("Error recording run of job %s: %v", j.name, err)
+	}
+	return run
+}
+
+func (s *Scheduler) call(j *scheduledJob) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.fn()
+}
+
+func (s *Scheduler) release(name string) {
+	if err := s.db.Model(&model.ScheduledJob{}).
+		Where("name = ? AND lease_owner = ?", name, s.instance).
+		Update("lease_expires_at", time.Time{}).Error; err != nil {
+		log.Printf("Error releasing lease for job %s: %v", name, err)
+	}
+}
+
+func (s *Scheduler) find(name string) *scheduledJob {
+	for _, j := range s.jobs {
+		if j.name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) Jobs() ([]dto.JobDTO, error) {
+	now := time.Now()
+	jobs := []dto.JobDTO{}
+	for _, j := range s.jobs {
+		var state model.ScheduledJob
+		if err := s.db.First(&state, "name = ?", j.name).Error; err != nil {
+			return nil, err
+		}
+		job := dto.JobDTO{
+			Name:      j.name,
+			Schedule:  j.spec,
+			NextRunAt: state.NextRunAt,
+			Running:   state.LeaseExpiresAt.After(now),
+		}
+		var last []model.JobRun
+		if err := s.db.Where("job_name = ?", j.name).Order("id desc").Limit(1).Find(&last).Error; err != nil {
+			return nil, err
+		}
+		if len(last) > 0 {
+			run := toJobRunDTO(last[0])
+			job.LastRun = &run
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (s *Scheduler) History(name string, limit int) ([]dto.JobRunDTO, error) {
+	if s.find(name) == nil {
+		return nil, ErrJobNotFound
+	}
+	var runs []model.JobRun
+	if err := s.db.Where("job_name = ?", name).Order("id desc").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	history := make([]dto.JobRunDTO, 0, len(runs))
+	for _, r := range runs {
+		history = append(history, toJobRunDTO(r))
+	}
+	return history, nil
+}
+
+func toJobRunDTO(r model.JobRun) dto.JobRunDTO {
+	run := dto.JobRunDTO{
+		ID:         r.ID,
+		JobName:    r.JobName,
+		Trigger:    string(r.Trigger),
+		Status:     string(r.Status),
+		Instance:   r.Instance,
+		Error:      r.Error,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}
+	if r.Result != "" {
+		run.Result = json.RawMessage(r.Result)
+	}
+	return run
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+// secretCipher deriva uma chave AES-GCM por finalidade, para segredos que precisam ser
+// lidos de volta. Cada finalidade tem seu segredo mestre (MFA_KEY_SECRET,
+// COUPON_KEY_SECRET), nenhum deles o JWT_SECRET, que precisa poder ser trocado.
+func secretCipher(purpose string) (cipher.AEAD, error) {
+	var master []byte
+	switch purpose {
+	case mfaSecretPurpose:
+		master = config.MFAKeySecret
+	case signingKeyPurpose:
+		master = config.CouponKeySecret
+	default:
+		return nil, errors.New("finalidade de segredo desconhecida: " + purpose)
+	}
+	key := sha256.Sum256(append([]byte(purpose+":"), master...))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sealSecret(purpose, secret string) (string, error) {
+	aead, err := secretCipher(purpose)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func openSecret(purpose, sealed string) (string, error) {
+	aead, err := secretCipher(purpose)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", errors.New("segredo cifrado corrompido")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
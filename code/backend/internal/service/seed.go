@@ -1,7 +1,10 @@
 package service
 
 import (
+	"campuscash-backend/config"
 	"campuscash-backend/internal/model"
+	"fmt"
+	"log"
 	"math/rand"
 	"time"
 
@@ -9,6 +12,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// Contas de demonstração já nascem com email verificado
+var seededAt = time.Now()
+
 func SeedInstituicoes(db *gorm.DB) {
 	institutions := []model.Institution{
 		{Name: "PUC Minas"},
@@ -60,11 +66,12 @@ func SeedProfessores(db *gorm.DB) {
 		if err := db.Where("email = ?", prof.Email).First(&existing).Error; err != nil {
 			hash, _ := bcrypt.GenerateFromPassword([]byte(prof.Password), bcrypt.DefaultCost)
 			db.Create(&model.User{
-				Name:         prof.Name,
-				Email:        prof.Email,
-				Role:         model.ProfessorRole,
-				PasswordHash: string(hash),
-				Balance:      prof.Balance,
+				Name:            prof.Name,
+				Email:           prof.Email,
+				Role:            model.ProfessorRole,
+				PasswordHash:    string(hash),
+				EmailVerifiedAt: &seededAt,
+				Balance:         prof.Balance,
 			})
 		}
 	}
@@ -114,23 +121,22 @@ func SeedAlunos(db *gorm.DB) {
 			hash, _ := bcrypt.GenerateFromPassword([]byte("aluno123"), bcrypt.DefaultCost)
 			
 			// Escolher instituição aleatória
-			var selectedInstitution string
+			var selectedInstitution *uint
 			if len(institutions) > 0 {
-				selectedInstitution = institutions[rand.Intn(len(institutions))].Name
-			} else {
-				selectedInstitution = student.Institution
+				selectedInstitution = &institutions[rand.Intn(len(institutions))].ID
 			}
 			
 			db.Create(&model.User{
-				Name:         student.Name,
-				Email:        student.Email,
-				CPF:          &student.CPF,
-				Role:         model.StudentRole,
-				PasswordHash: string(hash),
-				Registration: &student.Registration,
-				Institution:  &selectedInstitution,
-				Course:       &student.Course,
-				Balance:      student.Balance,
+				Name:            student.Name,
+				Email:           student.Email,
+				CPF:             &student.CPF,
+				Role:            model.StudentRole,
+				PasswordHash:    string(hash),
+				EmailVerifiedAt: &seededAt,
+				Registration:    &student.Registration,
+				InstitutionID:   selectedInstitution,
+				Course:          &student.Course,
+				Balance:         student.Balance,
 			})
 		}
 	}
@@ -161,13 +167,14 @@ func SeedEmpresas(db *gorm.DB) {
 		if err := db.Where("email = ?", company.Email).First(&existing).Error; err != nil {
 			hash, _ := bcrypt.GenerateFromPassword([]byte("empresa123"), bcrypt.DefaultCost)
 			db.Create(&model.User{
-				Name:         company.Name,
-				Email:        company.Email,
-				CPF:          &company.CNPJ,
-				Role:         model.CompanyRole,
-				PasswordHash: string(hash),
-				CompanyName:  &company.Name,
-				Balance:      company.Balance,
+				Name:            company.Name,
+				Email:           company.Email,
+				CPF:             &company.CNPJ,
+				Role:            model.CompanyRole,
+				PasswordHash:    string(hash),
+				EmailVerifiedAt: &seededAt,
+				CompanyName:     &company.Name,
+				Balance:         company.Balance,
 			})
 		}
 	}
@@ -225,6 +232,14 @@ func SeedRecompensas(db *gorm.DB) {
 }
 
 func SeedTransacoes(db *gorm.DB) {
+	// O histórico de demonstração já está nos saldos semeados; recriá-lo a cada início
+	// faria a conciliação acusar divergência
+	var count int64
+	db.Model(&model.Transaction{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
 	// Buscar usuários para criar transações
 	var students []model.User
 	var professors []model.User
@@ -256,6 +271,113 @@ func SeedTransacoes(db *gorm.DB) {
 	}
 }
 
+// SeedTurmas associa cada professor a uma instituição e abre uma turma para ele com
+// os alunos daquela instituição, para que a demonstração de envio de moedas funcione.
+// Fora do SeedAll: matricula todo mundo, então só roda com SEED_DEMO=true.
+func SeedTurmas(db *gorm.DB) {
+	var count int64
+	db.Model(&model.Class{}).Count(&count)
+	if count > 0 {
+		return
+	}
+	var institutions []model.Institution
+	var professors []model.User
+	db.Order("id").Find(&institutions)
+	db.Where("role = ?", model.ProfessorRole).Order("id").Find(&professors)
+	if len(institutions) == 0 {
+		return
+	}
+
+	for i, prof := range professors {
+		inst := institutions[i%len(institutions)]
+		if prof.InstitutionID != nil {
+			for _, candidate := range institutions {
+				if candidate.ID == *prof.InstitutionID {
+					inst = candidate
+				}
+			}
+		} else {
+			db.Model(&model.User{}).Where("id = ?", prof.ID).Update("institution_id", inst.ID)
+		}
+
+		var course model.Course
+		if err := db.Where("institution_id = ? AND code = ?", inst.ID, "ENG101").First(&course).Error; err != nil {
+			course = model.Course{InstitutionID: inst.ID, Code: "ENG101", Name: "Introdução à Engenharia"}
+			db.Create(&course)
+		}
+		class := model.Class{CourseID: course.ID, InstitutionID: inst.ID, ProfessorID: prof.ID, Name: fmt.Sprintf("Turma %d", i+1)}
+		db.Create(&class)
+
+		var students []model.User
+		db.Where("role = ? AND institution_id = ?", model.StudentRole, inst.ID).Find(&students)
+		for _, student := range students {
+			db.Create(&model.Enrollment{ClassID: class.ID, StudentID: student.ID})
+		}
+	}
+}
+
+func SeedSemestres(db *gorm.DB) {
+	year := time.Now().Year()
+	semesters := []model.Semester{
+		{Name: fmt.Sprintf("%d/1", year), StartsAt: time.Date(year, 1, 1, 0, 0, 0, 0, time.Local), EndsAt: time.Date(year, 7, 1, 0, 0, 0, 0, time.Local)},
+		{Name: fmt.Sprintf("%d/2", year), StartsAt: time.Date(year, 7, 1, 0, 0, 0, 0, time.Local), EndsAt: time.Date(year+1, 1, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, semester := range semesters {
+		var existing model.Semester
+		if err := db.Where("name = ? AND institution_id IS NULL", semester.Name).First(&existing).Error; err != nil {
+			db.Create(&semester)
+		}
+	}
+}
+
+func SeedPoliticas(db *gorm.DB) {
+	var count int64
+	db.Model(&model.AllotmentPolicy{}).Count(&count)
+	if count > 0 {
+		return
+	}
+	// Política global: moedas acumulam entre semestres
+	db.Create(&model.AllotmentPolicy{Amount: config.SemesterAllotment, Accumulate: true})
+}
+
+// SeedAdmin cria o primeiro administrador. Os demais são criados pela API de administração.
+// SeedAdmin cria o primeiro administrador com ADMIN_EMAIL/ADMIN_PASSWORD. Sem
+// ADMIN_PASSWORD, só a demonstração ganha um admin, com senha aleatória mostrada no log.
+func SeedAdmin(db *gorm.DB) {
+	var count int64
+	db.Model(&model.User{}).Where("role = ?", model.AdminRole).Count(&count)
+	if count > 0 {
+		return
+	}
+	password := config.AdminPassword
+	if password == "" {
+		if !config.SeedDemo {
+			log.Println("Warning: ADMIN_PASSWORD not set, no admin account was created")
+			return
+		}
+		var err error
+		if password, err = randomToken(8); err != nil {
+			log.Printf("Error generating demo admin password: %v", err)
+			return
+		}
+	}
+	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err := db.Create(&model.User{
+		Name:            "Administrador",
+		Email:           config.AdminEmail,
+		Role:            model.AdminRole,
+		PasswordHash:    string(hash),
+		EmailVerifiedAt: &seededAt,
+	}).Error; err != nil {
+		log.Printf("Error creating admin %s: %v", config.AdminEmail, err)
+		return
+	}
+	if config.AdminPassword == "" {
+		log.Printf("Demo admin %s created with password %s", config.AdminEmail, password)
+	}
+}
+
 func SeedAll(db *gorm.DB) {
 	SeedInstituicoes(db)
 	SeedProfessores(db)
@@ -263,6 +385,9 @@ func SeedAll(db *gorm.DB) {
 	SeedEmpresas(db)
 	SeedRecompensas(db)
 	SeedTransacoes(db)
+	SeedSemestres(db)
+	SeedPoliticas(db)
+	SeedAdmin(db)
 }
 
 
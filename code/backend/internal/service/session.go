@@ -0,0 +1,247 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = apperror.New("invalid_refresh_token", http.StatusUnauthorized)
+	ErrRefreshTokenReused  = apperror.New("refresh_token_reused", http.StatusUnauthorized)
+	ErrSessionRevoked      = apperror.New("session_revoked", http.StatusUnauthorized)
+)
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type SessionService struct {
+	db *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+func (s *SessionService) refreshTTL() time.Duration {
+	return time.Duration(config.RefreshTokenDays) * 24 * time.Hour
+}
+
+// Issue abre uma nova sessão para o usuário e retorna o primeiro par de tokens.
+func (s *SessionService) Issue(user *model.User, userAgent, ip string) (*dto.TokenPairDTO, error) {
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := model.Session{
+		ID:         sessionID,
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		ExpiresAt:  now.Add(s.refreshTTL()),
+		LastUsedAt: now,
+	}
+
+	var refresh string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		refresh, err = s.createRefreshToken(tx, sessionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenPair(user, sessionID, refresh)
+}
+
+func (s *SessionService) createRefreshToken(tx *gorm.DB, sessionID string) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = tx.Create(&model.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL()),
+	}).Error
+	return token, err
+}
+
+func (s *SessionService) tokenPair(user *model.User, sessionID, refresh string) (*dto.TokenPairDTO, error) {
+	ttl := time.Duration(config.AccessTokenMinutes) * time.Minute
+	claims := jwt.MapClaims{
+		"id":   user.ID,
+		"role": user.Role,
+		"sid":  sessionID,
+		"ver":  user.TokenVersion,
+		"exp":  time.Now().Add(ttl).Unix(),
+	}
+	// Empresas e funcionários levam a empresa e as permissões; mudanças de cargo
+	// revogam as sessões, então o token nunca fica com permissões antigas
+	if companyID, perms := model.CompanyScope(user); companyID != 0 {
+		claims["cid"] = companyID
+		claims["perms"] = perms
+	}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(config.JWTSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.TokenPairDTO{AccessToken: access, RefreshToken: refresh, ExpiresIn: int(ttl.Seconds())}, nil
+}
+
+// Refresh troca um refresh token por um novo par. O token apresentado é consumido;
+// se ele já tiver sido usado, a família inteira (sessão) é revogada.
+func (s *SessionService) Refresh(refresh string) (*dto.TokenPairDTO, error) {
+	var user model.User
+	var session model.Session
+	var next string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var token model.RefreshToken
+		if err := tx.Where("token_hash = ?", hashToken(refresh)).First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if err := tx.First(&session, "id = ?", token.SessionID).Error; err != nil {
+			return err
+		}
+		if session.RevokedAt != nil {
+			return ErrSessionRevoked
+		}
+
+		now := time.Now()
+		res := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+		if now.After(token.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		if err := tx.Omit("AvatarData").First(&user, session.UserID).Error; err != nil {
+			return err
+		}
+		if user.DeactivatedAt != nil {
+			return ErrSessionRevoked
+		}
+		if err := tx.Model(&model.Session{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"expires_at":   now.Add(s.refreshTTL()),
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		next, err = s.createRefreshToken(tx, session.ID)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// Fora da transação para que a revogação persista mesmo com o erro
+		log.Printf("Refresh token reuse detected for session %s (user %d)", session.ID, session.UserID)
+		if revokeErr := s.Revoke(session.ID); revokeErr != nil {
+			log.Printf("Error revoking session %s: %v", session.ID, revokeErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenPair(&user, session.ID, next)
+}
+
+// Revoke encerra uma sessão (logout).
+func (s *SessionService) Revoke(sessionID string) error {
+	return s.db.Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAll encerra todas as sessões do usuário e invalida os access tokens já emitidos.
+func (s *SessionService) RevokeAll(userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return revokeUserSessions(tx, userID)
+	})
+}
+
+func revokeUserSessions(tx *gorm.DB, userID uint) error {
+	if err := tx.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.User{}).Where("id = ?", userID).
+		Update("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+// Validate é chamado pelo middleware de autenticação a cada requisição.
+func (s *SessionService) Validate(userID uint, sessionID string, version uint) error {
+	var state struct {
+		TokenVersion         uint
+		DeactivatedAt        *time.Time
+		RevokedAt            *time.Time
+		CompanyDeactivatedAt *time.Time
+	}
+	// Funcionários perdem o acesso junto com a empresa
+	err := s.db.Table("users").
+		Select("users.token_version, users.deactivated_at, sessions.revoked_at, companies.deactivated_at AS company_deactivated_at").
+		Joins("JOIN sessions ON sessions.user_id = users.id AND sessions.id = ?", sessionID).
+		Joins("LEFT JOIN users AS companies ON companies.id = users.company_id").
+		Where("users.id = ?", userID).
+		Take(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	if state.RevokedAt != nil || state.DeactivatedAt != nil || state.CompanyDeactivatedAt != nil || state.TokenVersion != version {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// Cleanup remove sessões expiradas ou revogadas há mais de um dia.
+func (s *SessionService) Cleanup() (int64, error) {
+	now := time.Now()
+	var removed int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stale := tx.Model(&model.Session{}).Select("id").
+			Where("expires_at < ? OR revoked_at < ?", now, now.Add(-24*time.Hour))
+		if err := tx.Where("session_id IN (?)", stale).Delete(&model.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("expires_at < ? OR revoked_at < ?", now, now.Add(-24*time.Hour)).Delete(&model.Session{})
+		removed = res.RowsAffected
+		return res.Error
+	})
+	return removed, err
+}
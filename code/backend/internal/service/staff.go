@@ -0,0 +1,169 @@
+package service
+
+import (
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var ErrStaffNotFound = apperror.New("staff_not_found", http.StatusNotFound)
+
+func toStaffDTO(u model.User) dto.StaffDTO {
+	return dto.StaffDTO{
+		ID:        u.ID,
+		Name:      u.Name,
+		Email:     u.Email,
+		Role:      string(u.StaffRole),
+		Active:    u.DeactivatedAt == nil,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+// CompanyActive diz se a empresa do funcionário continua ativa; sem ela o funcionário
+// não entra.
+func CompanyActive(tx *gorm.DB, companyID *uint) bool {
+	if companyID == nil {
+		return false
+	}
+	var count int64
+	tx.Model(&model.User{}).
+		Where("id = ? AND role = ? AND deactivated_at IS NULL", *companyID, model.CompanyRole).
+		Count(&count)
+	return count > 0
+}
+
+// StaffService gerencia as contas de funcionários (caixas e gerentes) de uma empresa.
+type StaffService struct {
+	db *gorm.DB
+}
+
+func NewStaffService(db *gorm.DB) *StaffService {
+	return &StaffService{db: db}
+}
+
+func (s *StaffService) find(tx *gorm.DB, companyID, staffID uint) (*model.User, error) {
+	var user model.User
+	err := tx.Omit("AvatarData").
+		Where("id = ? AND role = ? AND company_id = ?", staffID, model.StaffUserRole, companyID).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStaffNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *StaffService) List(companyID uint) ([]dto.StaffDTO, error) {
+	var users []model.User
+	if err := s.db.Omit("AvatarData").
+		Where("role = ? AND company_id = ?", model.StaffUserRole, companyID).
+		Order("name").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	result := make([]dto.StaffDTO, len(users))
+	for i, u := range users {
+		result[i] = toStaffDTO(u)
+	}
+	return result, nil
+}
+
+// Create abre a conta do funcionário já com senha; ele entra pelo login normal.
+func (s *StaffService) Create(companyID uint, input dto.StaffCreateDTO) (*dto.StaffDTO, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user := model.User{
+		Name:            input.Name,
+		Email:           input.Email,
+		PasswordHash:    string(hash),
+		Role:            model.StaffUserRole,
+		CompanyID:       &companyID,
+		StaffRole:       model.StaffRole(input.Role),
+		EmailVerifiedAt: &now,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := EnsureUniqueUser(tx, 0, input.Email, "", ""); err != nil {
+			return err
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return UniqueViolation(err, "")
+		}
+		return recordAudit(tx, companyID, "staff.create", "user", &user.ID, map[string]interface{}{"email": user.Email, "role": user.StaffRole})
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := toStaffDTO(user)
+	return &result, nil
+}
+
+// ChangeRole troca o cargo; as sessões são revogadas porque as permissões vão no token.
+func (s *StaffService) ChangeRole(companyID, staffID uint, role string) (*dto.StaffDTO, error) {
+	var user *model.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = s.find(tx, companyID, staffID); err != nil {
+			return err
+		}
+		if user.StaffRole == model.StaffRole(role) {
+			return nil
+		}
+		previous := user.StaffRole
+		user.StaffRole = model.StaffRole(role)
+		if err := tx.Model(&model.User{}).Where("id = ?", staffID).Update("staff_role", user.StaffRole).Error; err != nil {
+			return err
+		}
+		if err := revokeUserSessions(tx, staffID); err != nil {
+			return err
+		}
+		return recordAudit(tx, companyID, "staff.change_role", "user", &staffID, map[string]interface{}{"from": previous, "to": user.StaffRole})
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := toStaffDTO(*user)
+	return &result, nil
+}
+
+// SetActive ativa ou desativa o funcionário; desativar encerra as sessões abertas.
+func (s *StaffService) SetActive(companyID, staffID uint, active bool) (*dto.StaffDTO, error) {
+	var user *model.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if user, err = s.find(tx, companyID, staffID); err != nil {
+			return err
+		}
+		action := "staff.activate"
+		user.DeactivatedAt = nil
+		if !active {
+			action = "staff.deactivate"
+			now := time.Now()
+			user.DeactivatedAt = &now
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", staffID).
+			Update("deactivated_at", user.DeactivatedAt).Error; err != nil {
+			return err
+		}
+		if !active {
+			if err := revokeUserSessions(tx, staffID); err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, companyID, action, "user", &staffID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := toStaffDTO(*user)
+	return &result, nil
+}
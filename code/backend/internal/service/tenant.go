@@ -0,0 +1,296 @@
+package service
+
+import (
+	"campuscash-backend/config"
+	"campuscash-backend/internal/apperror"
+	"campuscash-backend/internal/dto"
+	"campuscash-backend/internal/model"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrInstitutionChangeForbidden = apperror.New("institution_change_forbidden", http.StatusForbidden)
+
+const defaultCoinName = "moedas"
+
+// ResolveInstitution aceita o ID ou o nome da instituição; os formulários de
+// cadastro do frontend enviam o nome.
+func ResolveInstitution(tx *gorm.DB, ref string) (*model.Institution, error) {
+	ref = strings.TrimSpace(ref)
+	query := tx.Where("name = ?", ref)
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		query = tx.Where("id = ?", id)
+	}
+	var inst model.Institution
+	if err := query.First(&inst).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstitutionNotFound
+		}
+		return nil, err
+	}
+	return &inst, nil
+}
+
+// institutionOf carrega a instituição do usuário; nil quando ele não pertence a nenhuma.
+func institutionOf(tx *gorm.DB, user *model.User) (*model.Institution, error) {
+	if user.InstitutionID == nil {
+		return nil, nil
+	}
+	var inst model.Institution
+	if err := tx.First(&inst, *user.InstitutionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inst, nil
+}
+
+// changeInstitution aplica a instituição pedida em uma atualização de perfil. Só
+// contas ainda sem instituição podem escolher; trocar de tenant é tarefa do administrador.
+func changeInstitution(tx *gorm.DB, user *model.User, ref string) error {
+	if strings.TrimSpace(ref) == "" {
+		return nil
+	}
+	inst, err := ResolveInstitution(tx, ref)
+	if err != nil {
+		return err
+	}
+	if user.InstitutionID != nil && *user.InstitutionID != inst.ID {
+		return ErrInstitutionChangeForbidden
+	}
+	user.InstitutionID = &inst.ID
+	return nil
+}
+
+// institutionName devolve o nome exibido nos perfis, que ainda trazem o nome e não o ID.
+func institutionName(tx *gorm.DB, id *uint) string {
+	if id == nil {
+		return ""
+	}
+	var inst model.Institution
+	if err := tx.Select("name").First(&inst, *id).Error; err != nil {
+		return ""
+	}
+	return inst.Name
+}
+
+// TenantOf devolve a instituição do usuário; empresas e administradores não têm.
+func TenantOf(tx *gorm.DB, userID uint) (*uint, error) {
+	var user model.User
+	if err := tx.Select("id", "institution_id").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return user.InstitutionID, nil
+}
+
+// InTenant restringe uma consulta sobre users à instituição informada.
+func InTenant(institutionID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("users.institution_id = ?", institutionID)
+	}
+}
+
+// RewardsVisibleTo restringe uma consulta sobre rewards às vantagens que a instituição
+// enxerga. Sem instituição, só as vantagens abertas a todas são visíveis.
+func RewardsVisibleTo(institutionID *uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if institutionID == nil {
+			return db.Where("rewards.id NOT IN (SELECT reward_id FROM reward_institutions)")
+		}
+		return db.Where("(rewards.id NOT IN (SELECT reward_id FROM reward_institutions) OR "+
+			"rewards.id IN (SELECT reward_id FROM reward_institutions WHERE institution_id = ?))", *institutionID)
+	}
+}
+
+// RewardVisible diz se a vantagem está disponível para a instituição.
+func RewardVisible(tx *gorm.DB, rewardID uint, institutionID *uint) (bool, error) {
+	var count int64
+	err := tx.Model(&model.Reward{}).Scopes(RewardsVisibleTo(institutionID)).
+		Where("rewards.id = ?", rewardID).Count(&count).Error
+	return count > 0, err
+}
+
+// EnsureRewardVisibleTo recusa, como inexistente, uma vantagem restrita a outras
+// instituições que não a do usuário.
+func EnsureRewardVisibleTo(tx *gorm.DB, rewardID, userID uint) error {
+	institutionID, err := TenantOf(tx, userID)
+	if err != nil {
+		return err
+	}
+	visible, err := RewardVisible(tx, rewardID, institutionID)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrRewardNotFound
+	}
+	return nil
+}
+
+// SetRewardInstitutions substitui as instituições que enxergam a vantagem; lista
+// vazia a deixa aberta para todas.
+func SetRewardInstitutions(tx *gorm.DB, rewardID uint, institutionIDs []uint) error {
+	ids := make([]uint, 0, len(institutionIDs))
+	seen := make(map[uint]bool, len(institutionIDs))
+	for _, id := range institutionIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		var found []uint
+		if err := tx.Model(&model.Institution{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+			return err
+		}
+		if len(found) != len(ids) {
+			for _, id := range ids {
+				if !containsUint(found, id) {
+					return ErrInstitutionNotFound.With("institutionId", id)
+				}
+			}
+		}
+	}
+	if err := tx.Where("reward_id = ?", rewardID).Delete(&model.RewardInstitution{}).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := tx.Create(&model.RewardInstitution{RewardID: rewardID, InstitutionID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RewardInstitutionIDs agrupa, por vantagem, as instituições às quais ela está restrita.
+func RewardInstitutionIDs(tx *gorm.DB, rewardIDs []uint) (map[uint][]uint, error) {
+	result := make(map[uint][]uint)
+	if len(rewardIDs) == 0 {
+		return result, nil
+	}
+	var rows []model.RewardInstitution
+	if err := tx.Where("reward_id IN ?", rewardIDs).Order("institution_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.RewardID] = append(result[row.RewardID], row.InstitutionID)
+	}
+	return result, nil
+}
+
+func containsUint(list []uint, v uint) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// CoinNameFor é o nome da moeda na instituição do usuário, usado nas notificações.
+func CoinNameFor(tx *gorm.DB, userID uint) string {
+	var inst model.Institution
+	err := tx.Select("institutions.coin_name").
+		Joins("JOIN users ON users.institution_id = institutions.id").
+		Where("users.id = ?", userID).
+		First(&inst).Error
+	if err != nil || inst.CoinName == "" {
+		return defaultCoinName
+	}
+	return inst.CoinName
+}
+
+// MigrateUserInstitutions converte bancos antigos, em que users.institution guardava o
+// nome da instituição em texto livre, para a chave institution_id. Nomes sem cadastro
+// viram instituições novas para que nenhum vínculo se perca.
+func MigrateUserInstitutions(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&model.User{}, "institution") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO institutions (name, coin_expiry_days, allow_unenrolled_recipients, coin_name)
+			SELECT DISTINCT institution, 0, false, '' FROM users
+			WHERE institution IS NOT NULL AND institution <> ''
+			AND institution NOT IN (SELECT name FROM institutions)
+		`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`
+			UPDATE users SET institution_id = (SELECT id FROM institutions WHERE institutions.name = users.institution)
+			WHERE institution_id IS NULL AND institution IS NOT NULL AND institution <> ''
+		`).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&model.User{}, "institution")
+	})
+}
+
+type TenantService struct {
+	db *gorm.DB
+}
+
+func NewTenantService(db *gorm.DB) *TenantService {
+	return &TenantService{db: db}
+}
+
+// Settings reúne a configuração efetiva da instituição: nome da moeda, validade,
+// calendário (o próprio ou, na falta dele, o global) e políticas de crédito aplicáveis.
+func (s *TenantService) Settings(institutionID uint) (*dto.InstitutionSettingsDTO, error) {
+	var inst model.Institution
+	if err := s.db.First(&inst, institutionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInstitutionNotFound
+		}
+		return nil, err
+	}
+	settings := &dto.InstitutionSettingsDTO{
+		ID:                        inst.ID,
+		Name:                      inst.Name,
+		CoinName:                  inst.CoinName,
+		CoinExpiryDays:            inst.CoinExpiryDays,
+		AllowUnenrolledRecipients: inst.AllowUnenrolledRecipients,
+		Semesters:                 []model.Semester{},
+		AllotmentPolicies:         []model.AllotmentPolicy{},
+	}
+	if settings.CoinName == "" {
+		settings.CoinName = defaultCoinName
+	}
+	if settings.CoinExpiryDays == 0 {
+		settings.CoinExpiryDays = uint(config.CoinExpiryDays)
+	}
+	if err := s.db.Where("institution_id = ?", inst.ID).Order("starts_at").Find(&settings.Semesters).Error; err != nil {
+		return nil, err
+	}
+	if len(settings.Semesters) == 0 {
+		settings.SharedCalendar = true
+		if err := s.db.Where("institution_id IS NULL").Order("starts_at").Find(&settings.Semesters).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := s.db.Where("institution_id = ? OR institution_id IS NULL", inst.ID).Order("id").
+		Find(&settings.AllotmentPolicies).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// ForUser devolve a configuração da instituição do usuário logado.
+func (s *TenantService) ForUser(userID uint) (*dto.InstitutionSettingsDTO, error) {
+	institutionID, err := TenantOf(s.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if institutionID == nil {
+		return nil, ErrInstitutionNotFound
+	}
+	return s.Settings(*institutionID)
+}
@@ -1,13 +1,16 @@
 package service
 
 import (
+	"campuscash-backend/internal/apperror"
 	"campuscash-backend/internal/model"
-	"fmt"
+	"errors"
+	"net/http"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
+var ErrStudentNotFound = apperror.New("student_not_found", http.StatusNotFound)
+
 func SendCoins(db *gorm.DB, professorID, studentID uint, amount uint, message string) error {
 	return db.Transaction(func(tx *gorm.DB) error {
 		var prof, stud model.User
@@ -15,17 +18,15 @@ func SendCoins(db *gorm.DB, professorID, studentID uint, amount uint, message st
 			return err
 		}
 		if prof.Balance < amount {
-			return fmt.Errorf("not enough balance")
+			return ErrInsufficientBalance
 		}
 		if err := tx.First(&stud, studentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrStudentNotFound
+			}
 			return err
 		}
-		prof.Balance -= amount
-		stud.Balance += amount
-		if err := tx.Save(&prof).Error; err != nil {
-			return err
-		}
-		if err := tx.Save(&stud).Error; err != nil {
+		if err := ensureRewardable(tx, &prof, &stud); err != nil {
 			return err
 		}
 		tr := model.Transaction{
@@ -35,45 +36,7 @@ func SendCoins(db *gorm.DB, professorID, studentID uint, amount uint, message st
 			Message:    message,
 			Type:       model.GiveCoins,
 		}
-		if err := tx.Create(&tr).Error; err != nil {
-			return err
-		}
-
-		return nil
+		return RecordTransfer(tx, &tr)
 	})
 }
 
-
-func CreditProfessors(db *gorm.DB, amount uint) error {
-	var profs []model.User
-	if err := db.Where("role = ?", model.ProfessorRole).Find(&profs).Error; err != nil {
-		return err
-	}
-	for _, p := range profs {
-		err := db.Transaction(func(tx *gorm.DB) error {
-			var prof model.User
-			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&prof, p.ID).Error; err != nil {
-				return err
-			}
-			prof.Balance += amount
-			if err := tx.Save(&prof).Error; err != nil {
-				return err
-			}
-			tr := model.Transaction{
-				FromUserID: nil,
-				ToUserID:   &prof.ID,
-				Amount:     amount,
-				Message:    "Crédito semestral",
-				Type:       model.GiveCoins,
-			}
-			if err := tx.Create(&tr).Error; err != nil {
-				return err
-			}
-			return nil
-		})
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
@@ -16,7 +16,6 @@ type StudentService interface {
 	GetBalance(id uint) (*dto.StudentBalanceDTO, error)
 	UpdateProfile(id uint, input dto.StudentUpdateDTO) (*dto.StudentProfileDTO, error)
 	GetStatistics(id uint) (*dto.StudentStatisticsDTO, error)
-	SearchStudents(query string) ([]dto.StudentSearchDTO, error)
 }
 
 type studentService struct {
@@ -28,22 +27,34 @@ func NewStudentService(repo repository.StudentRepository, db *gorm.DB) StudentSe
 	return &studentService{repo, db}
 }
 
+// stringValue lê campos opcionais do usuário (CPF, RG, curso...) que podem estar nulos.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func (s *studentService) RegisterStudent(input dto.StudentRegisterDTO) (*model.User, error) {
+	inst, err := ResolveInstitution(s.db, input.Institution)
+	if err != nil {
+		return nil, err
+	}
 	pwHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 	student := &model.User{
-		Name:         input.Name,
-		Email:        input.Email,
-		PasswordHash: string(pwHash),
-		CPF:          &input.CPF,
-		RG:           &input.RG,
-		Address:      input.Address,
-		Institution:  &input.Institution,
-		Course:       &input.Course,
-		Role:         model.StudentRole,
-		Balance:      0,
+		Name:          input.Name,
+		Email:         input.Email,
+		PasswordHash:  string(pwHash),
+		CPF:           &input.CPF,
+		RG:            &input.RG,
+		Address:       input.Address,
+		InstitutionID: &inst.ID,
+		Course:        &input.Course,
+		Role:          model.StudentRole,
+		Balance:       0,
 	}
 	if err := s.repo.Create(student); err != nil {
 		return nil, err
@@ -60,11 +71,11 @@ func (s *studentService) GetProfile(id uint) (*dto.StudentProfileDTO, error) {
 		ID:          student.ID,
 		Name:        student.Name,
 		Email:       student.Email,
-		CPF:         *student.CPF,
-		RG:          *student.RG,
+		CPF:         stringValue(student.CPF),
+		RG:          stringValue(student.RG),
 		Address:     student.Address,
-		Institution: *student.Institution,
-		Course:      *student.Course,
+		Institution: institutionName(s.db, student.InstitutionID),
+		Course:      stringValue(student.Course),
 		Balance:     student.Balance,
 	}, nil
 }
@@ -74,7 +85,11 @@ func (s *studentService) GetBalance(id uint) (*dto.StudentBalanceDTO, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &dto.StudentBalanceDTO{Balance: student.Balance}, nil
+	expirations, err := activeLots(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.StudentBalanceDTO{Balance: student.Balance, Expirations: expirations}, nil
 }
 
 func (s *studentService) UpdateProfile(id uint, input dto.StudentUpdateDTO) (*dto.StudentProfileDTO, error) {
@@ -82,7 +97,9 @@ func (s *studentService) UpdateProfile(id uint, input dto.StudentUpdateDTO) (*dt
 	if err != nil {
 		return nil, err
 	}
-	
+	if err := EnsureUniqueUser(s.db, id, input.Email, input.CPF, "cpf"); err != nil {
+		return nil, err
+	}
 
 	if input.Name != "" {
 		student.Name = input.Name
@@ -99,26 +116,26 @@ func (s *studentService) UpdateProfile(id uint, input dto.StudentUpdateDTO) (*dt
 	if input.Address != "" {
 		student.Address = input.Address
 	}
-	if input.Institution != "" {
-		student.Institution = &input.Institution
+	if err := changeInstitution(s.db, student, input.Institution); err != nil {
+		return nil, err
 	}
 	if input.Course != "" {
 		student.Course = &input.Course
 	}
 	
 	if err := s.repo.Update(student); err != nil {
-		return nil, err
+		return nil, UniqueViolation(err, "cpf")
 	}
 	
 	return &dto.StudentProfileDTO{
 		ID:          student.ID,
 		Name:        student.Name,
 		Email:       student.Email,
-		CPF:         *student.CPF,
-		RG:          *student.RG,
+		CPF:         stringValue(student.CPF),
+		RG:          stringValue(student.RG),
 		Address:     student.Address,
-		Institution: *student.Institution,
-		Course:      *student.Course,
+		Institution: institutionName(s.db, student.InstitutionID),
+		Course:      stringValue(student.Course),
 		Balance:     student.Balance,
 	}, nil
 }
@@ -153,7 +170,8 @@ func (s *studentService) GetStatistics(id uint) (*dto.StudentStatisticsDTO, erro
 		SELECT COUNT(DISTINCT from_user_id) as count 
 		FROM transactions 
 		WHERE to_user_id = ? AND type = ? AND from_user_id IS NOT NULL
-	`, id, model.GiveCoins).Scan(&professoresUnicosResult).Error; err != nil {
+		AND (? IS NULL OR from_user_id IN (SELECT id FROM users WHERE institution_id = ?))
+	`, id, model.GiveCoins, student.InstitutionID, student.InstitutionID).Scan(&professoresUnicosResult).Error; err != nil {
 		professoresUnicosResult.Count = 0
 	}
 
@@ -166,11 +184,16 @@ func (s *studentService) GetStatistics(id uint) (*dto.StudentStatisticsDTO, erro
 	}
 
 	// Calcular rank (posição do aluno baseado no saldo total)
-	// Contar quantos alunos têm saldo maior que este aluno
+	// Contar quantos alunos da mesma instituição têm saldo maior que este aluno
 	var rankCount int64
-	if err := s.db.Model(&model.User{}).
-		Where("role = ? AND balance > ?", model.StudentRole, student.Balance).
-		Count(&rankCount).Error; err != nil {
+	rankQuery := s.db.Model(&model.User{}).
+		Where("role = ? AND balance > ?", model.StudentRole, student.Balance)
+	if student.InstitutionID != nil {
+		rankQuery = rankQuery.Scopes(InTenant(*student.InstitutionID))
+	} else {
+		rankQuery = rankQuery.Where("users.institution_id IS NULL")
+	}
+	if err := rankQuery.Count(&rankCount).Error; err != nil {
 		rankCount = 0
 	}
 	rank := uint(rankCount) + 1
@@ -183,21 +206,3 @@ func (s *studentService) GetStatistics(id uint) (*dto.StudentStatisticsDTO, erro
 		ResgatesRealizados: uint(resgatesRealizados),
 	}, nil
 }
-
-func (s *studentService) SearchStudents(query string) ([]dto.StudentSearchDTO, error) {
-	students, err := s.repo.SearchByNameOrEmail(query)
-	if err != nil {
-		return nil, err
-	}
-	
-	var result []dto.StudentSearchDTO
-	for _, student := range students {
-		result = append(result, dto.StudentSearchDTO{
-			ID:    student.ID,
-			Name:  student.Name,
-			Email: student.Email,
-		})
-	}
-	
-	return result, nil
-}
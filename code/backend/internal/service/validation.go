@@ -0,0 +1,79 @@
+package service
+
+import (
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/validator"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// EnsureUniqueUser recusa email ou documento (CPF/CNPJ, guardados na coluna cpf)
+// já usados por outro usuário. documentField é o nome do campo nos erros ("cpf" ou "cnpj").
+func EnsureUniqueUser(tx *gorm.DB, excludeID uint, email, document, documentField string) error {
+	var errs validator.Errors
+	var count int64
+	if email != "" {
+		if err := tx.Model(&model.User{}).Where("LOWER(email) = ? AND id <> ?", strings.ToLower(email), excludeID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			errs.Add("email", validator.CodeDuplicate, "email já cadastrado")
+		}
+	}
+	if document != "" {
+		if err := tx.Model(&model.User{}).Where("cpf = ? AND id <> ?", document, excludeID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			errs.Add(documentField, validator.CodeDuplicate, strings.ToUpper(documentField)+" já cadastrado")
+		}
+	}
+	return errs.Err()
+}
+
+// UniqueViolation converte a violação de índice único (quando outra requisição grava
+// o mesmo valor entre a checagem e o insert) no mesmo erro de campo de EnsureUniqueUser.
+func UniqueViolation(err error, documentField string) error {
+	if err == nil || !strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return err
+	}
+	var errs validator.Errors
+	switch {
+	case strings.Contains(err.Error(), "users.email"):
+		errs.Add("email", validator.CodeDuplicate, "email já cadastrado")
+	case strings.Contains(err.Error(), "users.cpf"):
+		errs.Add(documentField, validator.CodeDuplicate, strings.ToUpper(documentField)+" já cadastrado")
+	default:
+		return err
+	}
+	return errs
+}
+
+// NormalizeUserDocuments grava só os dígitos de CPF/CNPJ cadastrados antes da
+// normalização. Um documento cuja forma normalizada já pertence a outro usuário fica
+// como está e é registrado no log para revisão manual. É idempotente.
+func NormalizeUserDocuments(db *gorm.DB) error {
+	var users []model.User
+	if err := db.Select("id", "cpf").Where("cpf GLOB ?", "*[^0-9]*").Find(&users).Error; err != nil {
+		return err
+	}
+	for _, u := range users {
+		digits := validator.Digits(*u.CPF)
+		var count int64
+		if err := db.Model(&model.User{}).Where("cpf = ? AND id <> ?", digits, u.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			log.Printf("Skipping document of user %d: %s already belongs to another user", u.ID, digits)
+			continue
+		}
+		if err := db.Model(&model.User{}).Where("id = ?", u.ID).Update("cpf", digits).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bytes"
+	"campuscash-backend/config"
+	"campuscash-backend/internal/model"
+	"campuscash-backend/pkg/qr"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"net/url"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+)
+
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+const voucherDateLayout = "02/01/2006 15:04"
+
+var couponStatusLabels = map[model.CouponStatus]string{
+	model.CouponUsed:      "Cupom já utilizado",
+	model.CouponExpired:   "Cupom vencido",
+	model.CouponCancelled: "Cupom cancelado",
+	model.CouponRefunded:  "Cupom reembolsado",
+}
+
+// CouponQRContent é o que vai no QR code: o token assinado, que o PDV confere offline.
+// Cupons emitidos antes da assinatura levam o link da tela de validação da empresa.
+func CouponQRContent(coupon *model.Coupon) string {
+	if coupon.Token != "" {
+		return coupon.Token
+	}
+	return fmt.Sprintf("%s/empresa/validar?hash=%s", strings.TrimRight(config.FrontendURL, "/"), url.QueryEscape(coupon.Hash))
+}
+
+type couponVoucher struct {
+	coupon  model.Coupon
+	reward  model.Reward
+	company string
+}
+
+type VoucherService struct {
+	db *gorm.DB
+}
+
+func NewVoucherService(db *gorm.DB) *VoucherService {
+	return &VoucherService{db: db}
+}
+
+// load busca o cupom do aluno com a vantagem e o nome da empresa. Cupons de outros
+// alunos respondem como inexistentes.
+func (s *VoucherService) load(studentID, couponID uint) (*couponVoucher, error) {
+	var v couponVoucher
+	if err := s.db.Where("id = ? AND student_id = ?", couponID, studentID).First(&v.coupon).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+	if err := s.db.First(&v.reward, v.coupon.RewardID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var company model.User
+	if err := s.db.Select("id", "name", "company_name").First(&company, v.reward.CompanyID).Error; err == nil {
+		v.company = stringValue(company.CompanyName)
+		if v.company == "" {
+			v.company = company.Name
+		}
+	}
+	return &v, nil
+}
+
+// QR renderiza o QR code do cupom em PNG (size em pixels) ou SVG.
+func (s *VoucherService) QR(studentID, couponID uint, format string, size int) ([]byte, error) {
+	v, err := s.load(studentID, couponID)
+	if err != nil {
+		return nil, err
+	}
+	if format == QRFormatSVG {
+		return qr.SVG(CouponQRContent(&v.coupon), size)
+	}
+	return qr.PNG(CouponQRContent(&v.coupon), size)
+}
+
+// Voucher gera o PDF de uma página para o aluno imprimir ou apresentar no caixa.
+func (s *VoucherService) Voucher(studentID, couponID uint) ([]byte, error) {
+	v, err := s.load(studentID, couponID)
+	if err != nil {
+		return nil, err
+	}
+	matrix, err := qr.Matrix(CouponQRContent(&v.coupon))
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Voucher "+v.coupon.Code, true)
+	pdf.SetCreator("CampusCash", true)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+	// As fontes padrão do PDF são cp1252; o tradutor mantém os acentos
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageW, _ := pdf.GetPageSize()
+	contentW := pageW - 40
+
+	pdf.SetFillColor(37, 99, 235)
+	pdf.Rect(0, 0, pageW, 14, "F")
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetXY(20, 4)
+	pdf.CellFormat(contentW, 6, "CampusCash", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, tr("Voucher de vantagem"), "", 1, "R", false, 0, "")
+
+	pdf.SetTextColor(17, 24, 39)
+	pdf.SetXY(20, 24)
+	title := v.reward.Title
+	if title == "" {
+		title = "Vantagem removida"
+	}
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.MultiCell(contentW, 9, tr(title), "", "C", false)
+	if v.company != "" {
+		pdf.SetFont("Helvetica", "", 12)
+		pdf.SetTextColor(75, 85, 99)
+		pdf.MultiCell(contentW, 6, tr(v.company), "", "C", false)
+	}
+	y := pdf.GetY() + 6
+
+	if h := voucherImage(pdf, v.reward.ImageData, 20, y, contentW, 60); h > 0 {
+		y += h + 6
+	}
+
+	// QR desenhado em vetor, nítido em qualquer impressora
+	const qrSize = 70.0
+	module := qrSize / float64(len(matrix))
+	qrX := (pageW - qrSize) / 2
+	pdf.SetFillColor(0, 0, 0)
+	for row, line := range matrix {
+		for col, dark := range line {
+			if dark {
+				pdf.Rect(qrX+float64(col)*module, y+float64(row)*module, module, module, "F")
+			}
+		}
+	}
+	y += qrSize + 4
+
+	pdf.SetXY(20, y)
+	pdf.SetTextColor(17, 24, 39)
+	pdf.SetFont("Courier", "B", 22)
+	pdf.CellFormat(contentW, 10, v.coupon.Code, "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	validity := "Sem data de validade"
+	if v.coupon.ExpiresAt != nil {
+		validity = "Válido até " + v.coupon.ExpiresAt.Local().Format(voucherDateLayout)
+	}
+	pdf.CellFormat(contentW, 7, tr(validity), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(107, 114, 128)
+	pdf.CellFormat(contentW, 5, tr("Emitido em "+v.coupon.CreatedAt.Local().Format(voucherDateLayout)), "", 1, "C", false, 0, "")
+	if label, ok := couponStatusLabels[v.coupon.Status]; ok {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetTextColor(220, 38, 38)
+		pdf.CellFormat(contentW, 7, tr(label), "", 1, "C", false, 0, "")
+	}
+
+	pdf.SetXY(20, 270)
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.SetTextColor(107, 114, 128)
+	pdf.MultiCell(contentW, 4, tr("Apresente este voucher na empresa parceira. O QR code contém o cupom assinado "+
+		"pela empresa e pode ser conferido mesmo sem conexão; o código acima serve como alternativa."), "", "C", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// voucherImage encaixa a imagem da vantagem na caixa (x, y, w, h), centralizada, e
+// devolve a altura usada. Imagens que não decodificam são omitidas.
+func voucherImage(pdf *gofpdf.Fpdf, data []byte, x, y, w, h float64) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0
+	}
+	// Normaliza para PNG RGBA de 8 bits, que o gofpdf sempre aceita
+	rgba := image.NewNRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, rgba); err != nil {
+		return 0
+	}
+	options := gofpdf.ImageOptions{ImageType: "PNG"}
+	info := pdf.RegisterImageOptionsReader("reward", options, &buf)
+	if pdf.Err() {
+		pdf.ClearError()
+		return 0
+	}
+	iw, ih := info.Extent()
+	scale := w / iw
+	if ih*scale > h {
+		scale = h / ih
+	}
+	iw, ih = iw*scale, ih*scale
+	pdf.ImageOptions("reward", x+(w-iw)/2, y, iw, ih, false, options, 0, "")
+	return ih
+}
@@ -1,18 +1,34 @@
 package codegen
 
 import (
-    "math/rand"
-    "time"
+    "crypto/rand"
+    "encoding/hex"
     "fmt"
+    "math/big"
 )
 
 const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
-func GenerateCouponCode(prefix string) string {
-    rand.Seed(time.Now().UnixNano())
-    b := make([]byte, 8)
+// GenerateCouponCode sorteia o código com crypto/rand: o código sozinho dá direito à
+// vantagem, então não pode ser previsível.
+func GenerateCouponCode(prefix string) (string, error) {
+    b := make([]byte, 12)
+    max := big.NewInt(int64(len(charset)))
     for i := range b {
-        b[i] = charset[rand.Intn(len(charset))]
+        n, err := rand.Int(rand.Reader, max)
+        if err != nil {
+            return "", err
+        }
+        b[i] = charset[n.Int64()]
     }
-    return fmt.Sprintf("%s-%s", prefix, string(b))
-}
\ No newline at end of file
+    return fmt.Sprintf("%s-%s", prefix, string(b)), nil
+}
+
+// GenerateHash devolve 32 bytes aleatórios em hexadecimal, usados como hash do QR code.
+func GenerateHash() (string, error) {
+    b := make([]byte, 32)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,115 @@
+// Package coupontoken emite e confere os tokens assinados dos cupons CampusCash.
+//
+// O token é "CC1.<payload>.<assinatura>", com o payload em JSON e ambos em base64url.
+// A assinatura é Ed25519 com a chave da empresa dona da vantagem, então o PDV da
+// empresa consegue conferir um cupom sem consultar o servidor: basta manter em cache
+// as chaves públicas de GET /api/companies/:id/keys. Os cupons usados offline devem
+// ser enviados depois para POST /api/company/coupons/sync.
+package coupontoken
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Prefix identifica a versão do formato.
+const Prefix = "CC1"
+
+var (
+	ErrMalformed        = errors.New("coupontoken: token malformado")
+	ErrUnknownKey       = errors.New("coupontoken: chave desconhecida")
+	ErrInvalidSignature = errors.New("coupontoken: assinatura inválida")
+	ErrExpired          = errors.New("coupontoken: cupom vencido")
+)
+
+var encoding = base64.RawURLEncoding
+
+// Claims é o conteúdo assinado. Os nomes curtos mantêm o token pequeno o bastante para
+// caber em um QR code.
+type Claims struct {
+	CouponID  uint   `json:"c"`
+	RewardID  uint   `json:"r"`
+	StudentID uint   `json:"s"`
+	CompanyID uint   `json:"e"`
+	KeyID     string `json:"k"`
+	IssuedAt  int64  `json:"iat"`
+	// Unix; zero quando o cupom não vence
+	ExpiresAt int64 `json:"exp,omitempty"`
+}
+
+// Expired diz se o cupom já venceu no instante informado.
+func (c *Claims) Expired(now time.Time) bool {
+	return c.ExpiresAt != 0 && now.Unix() >= c.ExpiresAt
+}
+
+// KeySet reúne as chaves públicas de uma empresa pelo KeyID.
+type KeySet map[string]ed25519.PublicKey
+
+// Sign gera o token assinado com a chave privada da empresa.
+func Sign(key ed25519.PrivateKey, claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := Prefix + "." + encoding.EncodeToString(payload)
+	return signed + "." + encoding.EncodeToString(ed25519.Sign(key, []byte(signed))), nil
+}
+
+// Parse lê as claims sem conferir a assinatura; serve para descobrir KeyID e CompanyID
+// antes de escolher a chave. Nunca confie no resultado sem chamar Verify.
+func Parse(token string) (*Claims, error) {
+	claims, _, _, err := split(token)
+	return claims, err
+}
+
+// Verify confere a assinatura com as chaves da empresa e a validade no instante now.
+func Verify(token string, keys KeySet, now time.Time) (*Claims, error) {
+	claims, signed, signature, err := split(token)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keys[claims.KeyID]
+	if !ok || len(key) != ed25519.PublicKeySize {
+		return nil, ErrUnknownKey
+	}
+	if !ed25519.Verify(key, []byte(signed), signature) {
+		return nil, ErrInvalidSignature
+	}
+	if claims.Expired(now) {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+// ParsePublicKey decodifica uma chave pública no formato publicado pela API (base64).
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, ErrMalformed
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+func split(token string) (*Claims, string, []byte, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 || parts[0] != Prefix {
+		return nil, "", nil, ErrMalformed
+	}
+	payload, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, "", nil, ErrMalformed
+	}
+	signature, err := encoding.DecodeString(parts[2])
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, "", nil, ErrMalformed
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, "", nil, ErrMalformed
+	}
+	return &claims, parts[0] + "." + parts[1], signature, nil
+}
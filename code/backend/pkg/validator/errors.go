@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Códigos devolvidos em FieldError.Code; na API a mensagem é traduzida a partir deles.
+const (
+	CodeRequired  = "required"
+	CodeInvalid   = "invalid"
+	CodeTooShort  = "too_short"
+	CodeTooLong   = "too_long"
+	CodeWeak      = "weak"
+	CodeDuplicate = "duplicate"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// Errors acumula os problemas de todos os campos para devolvê-los de uma vez.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Code
+	}
+	return "dados inválidos (" + strings.Join(parts, ", ") + ")"
+}
+
+func (e *Errors) Add(field, code, message string) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err devolve nil quando não há erros, para uso direto em "if err := ...; err != nil".
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Conflict indica que todos os erros são de duplicidade (HTTP 409 em vez de 400).
+func (e Errors) Conflict() bool {
+	for _, fe := range e {
+		if fe.Code != CodeDuplicate {
+			return false
+		}
+	}
+	return len(e) > 0
+}
+
+var nonDigits = regexp.MustCompile(`\D`)
+
+// Digits remove pontuação de CPF/CNPJ ("529.982.247-25" vira "52998224725").
+func Digits(s string) string {
+	return nonDigits.ReplaceAllString(s, "")
+}
+
+// Required confere se o campo foi preenchido, já removendo espaços nas pontas.
+func (e *Errors) Required(field string, value *string) bool {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		e.Add(field, CodeRequired, "campo obrigatório")
+		return false
+	}
+	return true
+}
+
+// Email normaliza para minúsculas e confere o formato.
+func (e *Errors) Email(field string, value *string) {
+	*value = strings.ToLower(strings.TrimSpace(*value))
+	if _, err := mail.ParseAddress(*value); err != nil || !ValidateEmail(*value) {
+		e.Add(field, CodeInvalid, "email inválido")
+	}
+}
+
+// CPF normaliza para apenas dígitos e confere os dígitos verificadores.
+func (e *Errors) CPF(field string, value *string) {
+	*value = Digits(*value)
+	if !ValidateCPF(*value) {
+		e.Add(field, CodeInvalid, "CPF inválido")
+	}
+}
+
+// CNPJ normaliza para apenas dígitos e confere os dígitos verificadores.
+func (e *Errors) CNPJ(field string, value *string) {
+	*value = Digits(*value)
+	if !ValidateCNPJ(*value) {
+		e.Add(field, CodeInvalid, "CNPJ inválido")
+	}
+}
+
+func (e *Errors) Password(field, value string) {
+	if err := ValidatePassword(value); err != nil {
+		code := CodeWeak
+		if len(value) < 8 {
+			code = CodeTooShort
+		}
+		e.Add(field, code, err.Error())
+	}
+}
+
+func (e *Errors) MaxLength(field, value string, max int) {
+	if len([]rune(value)) > max {
+		e.Add(field, CodeTooLong, "texto muito longo")
+	}
+}
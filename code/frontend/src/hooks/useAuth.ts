@@ -16,7 +16,7 @@ export function useLogin() {
   return useMutation({
     mutationFn: (data: LoginRequest) => authService.login(data),
     onSuccess: (response) => {
-      authService.setToken(response.token);
+      authService.setToken(response.token, response.refreshToken);
       login(response.user, response.token);
       toast.success("Login realizado com sucesso!");
 
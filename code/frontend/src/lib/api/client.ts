@@ -1,23 +1,104 @@
-import { API_CONFIG } from "./config";
-import { ApiError } from "./types";
+import { useAuthStore } from "@/store";
+import { API_CONFIG, API_ENDPOINTS } from "./config";
+import { ApiError, TokenPairResponse } from "./types";
+
+export const TOKEN_KEY = "auth_token";
+export const REFRESH_TOKEN_KEY = "refresh_token";
+
+// Rotas em que um 401 é a resposta final, sem tentar renovar a sessão
+const NO_REFRESH_ENDPOINTS: string[] = [
+  API_ENDPOINTS.AUTH.LOGIN,
+  API_ENDPOINTS.AUTH.REFRESH,
+  API_ENDPOINTS.AUTH.LOGOUT,
+];
 
 class ApiClient {
   private baseURL: string;
   private timeout: number;
+  private refreshing: Promise<string | null> | null = null;
 
   constructor() {
     this.baseURL = API_CONFIG.BASE_URL;
     this.timeout = API_CONFIG.TIMEOUT;
   }
 
+  private getToken(): string | null {
+    return typeof window !== "undefined" ? localStorage.getItem(TOKEN_KEY) : null;
+  }
+
+  // Diz se a requisição que recebeu 401 deve ser repetida: outra chamada já trocou o
+  // token enquanto esta estava em andamento, ou a renovação feita agora deu certo
+  private async renewed(endpoint: string, token: string | null): Promise<boolean> {
+    if (!token || NO_REFRESH_ENDPOINTS.includes(endpoint)) {
+      return false;
+    }
+    const current = this.getToken();
+    if (current && current !== token) {
+      return true;
+    }
+    return !!(await this.refreshAccessToken());
+  }
+
+  // O access token dura poucos minutos; ao receber 401, troca o refresh token por um
+  // par novo. Requisições simultâneas compartilham a mesma renovação, já que o refresh
+  // token é rotativo e só pode ser usado uma vez.
+  private refreshAccessToken(): Promise<string | null> {
+    if (!this.refreshing) {
+      this.refreshing = this.doRefresh().finally(() => {
+        this.refreshing = null;
+      });
+    }
+    return this.refreshing;
+  }
+
+  private async doRefresh(): Promise<string | null> {
+    const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY);
+    if (!refreshToken) {
+      this.endSession();
+      return null;
+    }
+    try {
+      const response = await fetch(
+        `${this.baseURL}${API_ENDPOINTS.AUTH.REFRESH}`,
+        {
+          method: "POST",
+          headers: API_CONFIG.HEADERS,
+          body: JSON.stringify({ refreshToken }),
+        }
+      );
+      if (!response.ok) {
+        this.endSession();
+        return null;
+      }
+      const tokens: TokenPairResponse = await response.json();
+      localStorage.setItem(TOKEN_KEY, tokens.token);
+      localStorage.setItem(REFRESH_TOKEN_KEY, tokens.refreshToken);
+      useAuthStore.getState().setToken(tokens.token);
+      return tokens.token;
+    } catch {
+      // Falha de rede: mantém a sessão para tentar de novo depois
+      return null;
+    }
+  }
+
+  // Sessão expirada ou revogada: limpa os tokens e volta para o login
+  private endSession(): void {
+    localStorage.removeItem(TOKEN_KEY);
+    localStorage.removeItem(REFRESH_TOKEN_KEY);
+    useAuthStore.getState().clearAuth();
+    if (!window.location.pathname.startsWith("/login")) {
+      window.location.href = "/login";
+    }
+  }
+
   private async request<T>(
     endpoint: string,
-    options: RequestInit = {}
+    options: RequestInit = {},
+    retry = true
   ): Promise<T> {
     const url = `${this.baseURL}${endpoint}`;
 
-    const token =
-      typeof window !== "undefined" ? localStorage.getItem("auth_token") : null;
+    const token = this.getToken();
 
     const config: RequestInit = {
       ...options,
@@ -39,6 +120,14 @@ class ApiClient {
 
       clearTimeout(timeoutId);
 
+      if (
+        response.status === 401 &&
+        retry &&
+        (await this.renewed(endpoint, token))
+      ) {
+        return this.request<T>(endpoint, options, false);
+      }
+
       if (!response.ok) {
         const errorData: ApiError = await response.json().catch(() => ({
           error: "Erro de rede",
@@ -95,12 +184,16 @@ class ApiClient {
     return this.request<T>(endpoint, { method: "DELETE" });
   }
 
-  async upload<T>(endpoint: string, file: File, fieldName: string = "file"): Promise<T> {
+  async upload<T>(
+    endpoint: string,
+    file: File,
+    fieldName: string = "file",
+    retry = true
+  ): Promise<T> {
     const formData = new FormData();
     formData.append(fieldName, file);
 
-    const token =
-      typeof window !== "undefined" ? localStorage.getItem("auth_token") : null;
+    const token = this.getToken();
 
     const response = await fetch(`${this.baseURL}${endpoint}`, {
       method: "POST",
@@ -110,6 +203,14 @@ class ApiClient {
       body: formData,
     });
 
+    if (
+      response.status === 401 &&
+      retry &&
+      (await this.renewed(endpoint, token))
+    ) {
+      return this.upload<T>(endpoint, file, fieldName, false);
+    }
+
     if (!response.ok) {
       const errorData: ApiError = await response.json().catch(() => ({
         error: "Erro no upload",
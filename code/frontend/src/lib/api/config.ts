@@ -9,6 +9,8 @@ export const API_CONFIG = {
 export const API_ENDPOINTS = {
   AUTH: {
     LOGIN: "/api/auth/login",
+    REFRESH: "/api/auth/refresh",
+    LOGOUT: "/api/auth/logout",
     SIGNUP_STUDENT: "/api/auth/signup/student",
     SIGNUP_COMPANY: "/api/auth/signup/company",
     ME: "/api/auth/me",
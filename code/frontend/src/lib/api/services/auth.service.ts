@@ -1,4 +1,4 @@
-import { apiClient } from "../client";
+import { apiClient, REFRESH_TOKEN_KEY, TOKEN_KEY } from "../client";
 import { API_ENDPOINTS } from "../config";
 import {
   LoginRequest,
@@ -27,19 +27,27 @@ export class AuthService {
 
   logout(): void {
     if (typeof window !== "undefined") {
-      localStorage.removeItem("auth_token");
+      // Revoga a sessão no servidor; os tokens locais saem de qualquer forma
+      if (this.getToken()) {
+        apiClient.post(API_ENDPOINTS.AUTH.LOGOUT).catch(() => undefined);
+      }
+      localStorage.removeItem(TOKEN_KEY);
+      localStorage.removeItem(REFRESH_TOKEN_KEY);
     }
   }
 
-  setToken(token: string): void {
+  setToken(token: string, refreshToken?: string): void {
     if (typeof window !== "undefined") {
-      localStorage.setItem("auth_token", token);
+      localStorage.setItem(TOKEN_KEY, token);
+      if (refreshToken) {
+        localStorage.setItem(REFRESH_TOKEN_KEY, refreshToken);
+      }
     }
   }
 
   getToken(): string | null {
     if (typeof window !== "undefined") {
-      return localStorage.getItem("auth_token");
+      return localStorage.getItem(TOKEN_KEY);
     }
     return null;
   }
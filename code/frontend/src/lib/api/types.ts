@@ -103,9 +103,17 @@ export interface LoginRequest {
 
 export interface LoginResponse {
   token: string;
+  refreshToken: string;
+  expiresIn: number;
   user: User;
 }
 
+export interface TokenPairResponse {
+  token: string;
+  refreshToken: string;
+  expiresIn: number;
+}
+
 export interface SignupStudentRequest {
   name: string;
   email: string;